@@ -2,12 +2,90 @@ package compiler
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 )
 
+// pipeStages drops unbound stages from ExecPipe arguments.
+func pipeStages(arg []string) []string {
+	commands := make([]string, 0, len(arg))
+	for _, command := range arg {
+		if command != "" {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+// signalArgs returns the signals handled by signal.Notify and others. All catchable signals are not listed, so common ones are used by default.
+func signalArgs(arg []string) []string {
+	var sigs []string
+	for _, sig := range arg {
+		if sig != "" {
+			sigs = append(sigs, trimQuote(sig))
+		}
+	}
+	if len(sigs) == 0 {
+		return []string{"1", "2", "3", "15"}
+	}
+	return sigs
+}
+
 // TODO: export types to modify from outside
 var InitBuiltInFuncs = func(s *state) {
+	// runCmd runs the exec.Cmd receiver. A command created in the same expression is read from GOTOSH_RET_0.
+	runCmd := func(name, mode string) func(e *shExpression, args []*shExpression) {
+		return func(e *shExpression, args []*shExpression) {
+			if strings.HasPrefix(args[0].expr, RET_PREFIX) {
+				e.expr = args[0].expr + "; " + s.useRuntime(name) + " " + RET_PREFIX + "0 " + mode
+			} else {
+				e.expr = s.useRuntime(name) + " " + varName(args[0].expr) + " " + mode
+			}
+		}
+	}
+	// defineFlag registers a flag. The *Var forms take the variable as the first argument.
+	defineFlag := func(kind string, hasVar bool) func(e *shExpression, arg []string) {
+		return func(e *shExpression, arg []string) {
+			v := `""`
+			if hasVar {
+				v, arg = arg[0], arg[1:]
+			}
+			if name, ok := unquoteShellString(arg[0]); ok {
+				usage, _ := unquoteShellString(arg[2])
+				s.flags = append(s.flags, flagDef{name: name, kind: kind, usage: usage})
+			}
+			e.expr = s.useRuntime("flag.define") + " " + kind + " " + strings.Join(arg, " ") + " " + v
+		}
+	}
+	// sliceArgs returns the elements of a slice argument. A slice returned in GOTOSH_RET_0 is read after the call.
+	sliceArgs := func(a *shExpression) (string, string) {
+		if a.primaryIdx < 0 && a.expr != "" && len(a.retTypes) > 0 && s.IsType(a.retTypes[0], TYPE_ARRAY) {
+			return a.expr + "; ", `"${` + a.RetVarName(0) + `[@]}"`
+		}
+		return "", strings.Join(a.Values(), " ")
+	}
+	// sortKind returns the kind of elements for the sort runtime: i (integer), f (float) or s (string).
+	sortKind := func(t Type) string {
+		switch et := s.resolveType(t.ElementType()); {
+		case et == "float64" || et == "float32":
+			return "f"
+		case s.isIntType(et):
+			return "i"
+		}
+		return "s"
+	}
+	// sortInPlace sorts the slice variable by the sort.sorted runtime.
+	sortInPlace := func(kind string) func(e *shExpression, args []*shExpression) {
+		return func(e *shExpression, args []*shExpression) {
+			name := varName(args[0].expr)
+			if kind == "" {
+				kind = sortKind(args[0].retTypes[0])
+			}
+			e.expr = s.useRuntime("sort.sorted") + " " + kind + ` "${` + name + `[@]}"; ` + name + `=("${` + RET_PREFIX + `0[@]}")`
+		}
+	}
 	s.funcs = map[string]shExpression{
 		"nil":   {expr: "0", typ: "VALUE", retTypes: []Type{""}},
 		"true":  {expr: "1", typ: "VALUE", retTypes: []Type{"bool"}},
@@ -19,14 +97,32 @@ var InitBuiltInFuncs = func(s *state) {
 			}
 			e.expr = strings.Join(append([]string{arg[0], "0", "1"}, arg[1:]...), " ")
 		}},
+		"shell.Cmd": {retTypes: []Type{"StageCall"}, primaryIdx: 0, applyFunc: func(e *shExpression, arg []string) {
+			e.expr = strings.Join(arg, " ")
+		}},
+		"shell.BindCmd": {retTypes: []Type{"StageCall"}, primaryIdx: 0, applyFunc2: func(e *shExpression, args []*shExpression) {
+			runCmd("exec.run", "pipe")(e, args)
+			if strings.Contains(e.expr, ";") {
+				e.expr = "{ " + e.expr + "; }"
+			}
+		}},
 		"shell.ExecPipe": {retTypes: []Type{"StatusCode"}, primaryIdx: -1, applyFunc: func(e *shExpression, arg []string) {
-			commands := make([]string, 0, len(arg))
-			for _, command := range arg {
-				if command != "" {
-					commands = append(commands, command)
-				}
+			e.expr = strings.Join(pipeStages(arg), " | ")
+		}},
+		"shell.ExecPipeAll": {retTypes: []Type{"[]StatusCode"}, primaryIdx: -1, applyFunc: func(e *shExpression, arg []string) {
+			commands := pipeStages(arg)
+			if len(commands) == 0 {
+				e.expr = RET_PREFIX + "0=()"
+				return
 			}
-			e.expr = strings.Join(commands, " | ")
+			// PIPESTATUS is bash only. Other shells record each stage's status in a file.
+			recorded := make([]string, len(commands))
+			for i, command := range commands {
+				recorded[i] = "{ " + command + `; echo "$?" >&3; } 3>"$GOTOSH_PIPE_DIR/` + strconv.Itoa(i) + `"`
+			}
+			e.expr = `if [ -n "${BASH_VERSION-}" ]; then ` + strings.Join(commands, " | ") + "; " + RET_PREFIX + `0=("${PIPESTATUS[@]}"); else ` +
+				"GOTOSH_PIPE_DIR=$(mktemp -d); " + strings.Join(recorded, " | ") + "; " +
+				s.useRuntime("shell.pipestatus") + ` "$GOTOSH_PIPE_DIR" ` + strconv.Itoa(len(commands)) + "; fi"
 		}},
 		"shell.Sleep":         {expr: "sleep"},
 		"shell.Exit":          {expr: "exit"},
@@ -39,41 +135,29 @@ var InitBuiltInFuncs = func(s *state) {
 		"shell.Args":          {expr: `"$@"`, retTypes: []Type{"[]string"}},
 		"shell.SetArgs":       {expr: `set -- `},
 		"shell.NArgs":         {expr: `$(( $# + 1 ))`, retTypes: []Type{"int"}},
-		"shell.UnixTimeMs":    {expr: `printf '%.0f' $( echo "${EPOCHREALTIME:-$(date +%s)} * 1000" | bc )`, retTypes: []Type{"int"}, stdout: true},
+		"shell.UnixTimeMs":    {expr: `awk 'BEGIN { printf "%.0f", ARGV[1] * 1000 }' "${EPOCHREALTIME:-$(date +%s)}"`, retTypes: []Type{"int"}, stdout: true},
 		"shell.Do":            {retTypes: []Type{"StatusCode"}, applyFunc: func(e *shExpression, arg []string) { e.expr = strings.TrimSpace(trimQuote(arg[0])) }, primaryIdx: -1},
 		"shell.IsShellScript": {expr: "1", typ: "VALUE", retTypes: []Type{"bool"}},
 
 		"shell.SetFloatPrecision": {applyFunc: func(e *shExpression, arg []string) {
 			if p, err := strconv.Atoi(arg[0]); err == nil && p >= 0 {
-				asValueFunc["FLOAT_EXPR"] = func(e *shExpression) string {
-					return `$(echo "scale=` + strconv.Itoa(p) + `;` + e.expr + `" | BC_LINE_LENGTH=` + strconv.Itoa(p+10) + ` bc -l)`
-				}
+				s.floatPrecision = p
+				e.expr = "GOTOSH_FLOAT_PREC=" + arg[0] // fmt prints all digits
 			} else {
-				asValueFunc["FLOAT_EXPR"] = func(e *shExpression) string { return `$(echo "` + e.expr + `" | bc -l)` }
+				s.floatPrecision = -1
+				e.expr = "GOTOSH_FLOAT_PREC="
 			}
 		}, retTypes: []Type{"struct{:}"}, primaryIdx: -1},
 		"shell.Files": {applyFunc: func(e *shExpression, arg []string) { e.expr = trimQuote(arg[0]) }, retTypes: []Type{"[]string"}},
-		// fmt
-		"fmt.Print":   {expr: "echo -n"},
-		"fmt.Println": {expr: "echo"},
-		"fmt.Printf":  {expr: "printf"},
-		"fmt.Sprint":  {expr: "echo -n", retTypes: []Type{"string"}, stdout: true},
-		"fmt.Sprintln": {retTypes: []Type{"string"}, applyFunc: func(e *shExpression, arg []string) {
-			e.expr = "$(echo " + strings.Join(arg, " ") + ")$'\\n'"
-		}},
-		"fmt.Sprintf":  {expr: "printf", retTypes: []Type{"string"}, stdout: true},
-		"fmt.Fprint":   {applyFunc: func(e *shExpression, arg []string) { e.expr = "echo -n " + strings.Join(arg[1:], " ") + " >&" + arg[0] }},
-		"fmt.Fprintln": {applyFunc: func(e *shExpression, arg []string) { e.expr = "echo " + strings.Join(arg[1:], " ") + " >&" + arg[0] }},
-		"fmt.Fprintf":  {applyFunc: func(e *shExpression, arg []string) { e.expr = "printf " + strings.Join(arg[1:], " ") + " >&" + arg[0] }},
 		// strings
-		"strings.Split": {retTypes: []Type{"[]string"}, stdout: true, applyFunc: func(e *shExpression, arg []string) {
-			e.expr = "IFS=" + arg[1] + " _tmp0=(" + trimQuote(arg[0]) + ") ;echo \"${_tmp0[@]}\""
-		}},
-		"strings.Join": {expr: "IFS={1} {0R}=\"${{*0}[*]}\"", retTypes: []Type{"string"}, primaryIdx: -1, template: true},
+		"strings.Builder.WriteString": {expr: "{*0}={0}{1}", template: true},
+		"strings.Builder.String":      {expr: "{0}", retTypes: []Type{"string"}, template: true},
+		"strings.Builder.Len":         {expr: "${#{*0}}", retTypes: []Type{"int"}, template: true},
+		"strings.Builder.Reset":       {expr: "{*0}=", template: true},
 		// os
 		"os.Stdin":    {expr: "0", typ: "VALUE", retTypes: []Type{"*os.File"}},
 		"os.Stdout":   {expr: "1", typ: "VALUE", retTypes: []Type{"*os.File"}},
-		"os.Stderr":   {expr: "1", typ: "VALUE", retTypes: []Type{"*os.File"}},
+		"os.Stderr":   {expr: "2", typ: "VALUE", retTypes: []Type{"*os.File"}},
 		"os.Args":     {expr: `"$0" "$@"`, typ: "VALUE", retTypes: []Type{"[]string"}},
 		"os.Exit":     {expr: "exit"},
 		"os.Getwd":    {expr: "pwd", retTypes: []Type{"string", "StatusCode"}, stdout: true},
@@ -94,50 +178,307 @@ var InitBuiltInFuncs = func(s *state) {
 		"os.Pipe": {expr: `_tmp=$(mktemp -d) && mkfifo $_tmp/f && {0R}=$(( GOTOSH_fd=${GOTOSH_fd:-2}+1 )) && {1R}=$(( ++GOTOSH_fd ))` +
 			` && eval "exec ${1R}<>\"$_tmp/f\" ${0R}<\"$_tmp/f\"" && rm -rf $_tmp`,
 			retTypes: []Type{"*os.File", "*os.File", "StatusCode"}, primaryIdx: -1, template: true},
-		"os.Open":             {expr: `{0R}=$(( GOTOSH_fd=${GOTOSH_fd:-2}+1 )); eval "exec ${0R}<'{0}'"`, retTypes: []Type{"*os.File", "StatusCode"}, primaryIdx: -1, template: true},
-		"os.Create":           {expr: `{0R}=$(( GOTOSH_fd=${GOTOSH_fd:-2}+1 )); eval "exec ${0R}>'{0}'"`, retTypes: []Type{"*os.File", "StatusCode"}, primaryIdx: -1, template: true},
-		"os.Stat":             {expr: `[ -e {0} ] && echo {0}`, retTypes: []Type{"fs.FileInfo", "StatusCode"}, stdout: true, template: true},
-		"fs.FileInfo.Name":    {expr: `basename {0}`, retTypes: []Type{"string"}, stdout: true, template: true},
-		"fs.FileInfo.Size":    {expr: `stat -c %s {0} 2>/dev/null || stat -f %z {0}`, retTypes: []Type{"int"}, stdout: true, template: true},
-		"fs.FileInfo.Mode":    {typ: "INT_EXPR", expr: `8#$(stat -c %a {0} 2>/dev/null || stat -f %p {0})`, retTypes: []Type{"int"}, template: true},
-		"fs.FileInfo.IsDir":   {expr: `[ -d {0} ] && echo 1 || echo 0`, retTypes: []Type{"bool"}, stdout: true, template: true},
-		"os.Mkdir":            {expr: "mkdir {0}", retTypes: []Type{"StatusCode"}, template: true},
-		"os.MkdirAll":         {expr: "mkdir -p {0}", retTypes: []Type{"StatusCode"}, template: true},
-		"os.Remove":           {expr: "rm -f", retTypes: []Type{"StatusCode"}},
-		"os.RemoveAll":        {expr: "rm -rf", retTypes: []Type{"StatusCode"}},
-		"os.Rename":           {expr: "mv", retTypes: []Type{"StatusCode"}},
-		"os.File.WriteString": {expr: `echo -n {1} >&{0}`, template: true},
-		"os.File.Close":       {expr: `eval "exec {0}<&- {0}>&-"`, template: true},
-		"os.File.Fd":          {expr: `{0}`, retTypes: []Type{"int"}, template: true},
-		"exec.Command":        {expr: "echo -n ", retTypes: []Type{"*exec.Cmd"}, stdout: true}, // TODO escape command string...
-		"exec.Cmd.Output":     {expr: "bash -c", retTypes: []Type{"string", "StatusCode"}, stdout: true},
-		"reflect.TypeOf":      {retTypes: []Type{"string"}, applyFunc: func(e *shExpression, arg []string) { e.expr = `"` + string(s.vars[varName(arg[0])].Type) + `"` }},
-		"runtime.Compiler":    {expr: "'gotosh'", typ: "VALUE", retTypes: []Type{"string"}},               // constant
-		"runtime.GOARCH":      {expr: "uname -m", typ: "VALUE", retTypes: []Type{"string"}, stdout: true}, // constant
-		"runtime.GOOS":        {expr: "uname -o", typ: "VALUE", retTypes: []Type{"string"}, stdout: true}, // constant
-		// math (using bc)
-		"math.Pi":   {expr: "3.141592653589793", typ: "VALUE", retTypes: []Type{"float64"}}, // constant
-		"math.E":    {expr: "2.718281828459045", typ: "VALUE", retTypes: []Type{"float64"}}, // constant
-		"math.Sqrt": {typ: "FLOAT_EXPR", expr: "sqrt({0F})", retTypes: []Type{"float64"}, template: true},
-		"math.Pow":  {typ: "FLOAT_EXPR", expr: "e(l({0F})*{1F})", retTypes: []Type{"float64"}, template: true},
-		"math.Exp":  {typ: "FLOAT_EXPR", expr: "e({0F})", retTypes: []Type{"float64"}, template: true},
-		"math.Log":  {typ: "FLOAT_EXPR", expr: "l({0F})", retTypes: []Type{"float64"}, template: true},
-		"math.Sin":  {typ: "FLOAT_EXPR", expr: "s({0F})", retTypes: []Type{"float64"}, template: true},
-		"math.Cos":  {typ: "FLOAT_EXPR", expr: "c({0F})", retTypes: []Type{"float64"}, template: true},
-		"math.Tan":  {typ: "FLOAT_EXPR", expr: "x={0F}; s(x)/c(x)", retTypes: []Type{"float64"}, template: true},
-		"math.Atan": {typ: "FLOAT_EXPR", expr: "a({0F})", retTypes: []Type{"float64"}, template: true},
-		"math.Sinh": {typ: "FLOAT_EXPR", expr: "x={0F}; ((e(x)-e(-x))/2)", retTypes: []Type{"float64"}, template: true},
-		"math.Cosh": {typ: "FLOAT_EXPR", expr: "x={0F}; ((e(x)+e(-x))/2)", retTypes: []Type{"float64"}, template: true},
-		"math.Tanh": {typ: "FLOAT_EXPR", expr: "x={0F}; ((e(x)-e(-x))/(e(x)+e(-x)))", retTypes: []Type{"float64"}, template: true},
+		"os.Open":                    {expr: `{0R}=$(( GOTOSH_fd=${GOTOSH_fd:-2}+1 )); if [ -e {0} ]; then eval "exec ${0R}<'{0}'"; else (exit 2); fi`, retTypes: []Type{"*os.File", "StatusCode"}, primaryIdx: -1, template: true},
+		"os.Create":                  {expr: `{0R}=$(( GOTOSH_fd=${GOTOSH_fd:-2}+1 )); eval "exec ${0R}>'{0}'"`, retTypes: []Type{"*os.File", "StatusCode"}, primaryIdx: -1, template: true},
+		"fs.FileInfo.Name":           {expr: `"${{*0}__name}"`, retTypes: []Type{"string"}, template: true},
+		"fs.FileInfo.Size":           {expr: `${{*0}__size}`, retTypes: []Type{"int"}, template: true},
+		"fs.FileInfo.Mode":           {expr: `${{*0}__mode}`, retTypes: []Type{"fs.FileMode"}, template: true},
+		"fs.FileInfo.ModTime":        {expr: `${{*0}__modTime}`, retTypes: []Type{"time.Time"}, template: true},
+		"fs.FileInfo.IsDir":          {expr: `$(( {*0}__mode >> 31 & 1 ))`, retTypes: []Type{"bool"}, template: true},
+		"fs.FileMode.IsDir":          {expr: `$(( {0} >> 31 & 1 ))`, retTypes: []Type{"bool"}, template: true},
+		"fs.FileMode.IsRegular":      {expr: `$(( ({0} & 8#37777777000) == 0 ))`, retTypes: []Type{"bool"}, template: true},
+		"fs.FileMode.Perm":           {expr: `$(( {0} & 8#777 ))`, retTypes: []Type{"fs.FileMode"}, template: true},
+		"fs.DirEntry.Name":           {expr: `"${{*0}##*/}"`, retTypes: []Type{"string"}, template: true},
+		"fs.DirEntry.IsDir":          {expr: `$(( ${{*0}%%/*} >> 31 & 1 ))`, retTypes: []Type{"bool"}, template: true},
+		"fs.DirEntry.Type":           {expr: `${{*0}%%/*}`, retTypes: []Type{"fs.FileMode"}, template: true},
+		"os.IsNotExist":              {expr: `$(( {0} == 2 ))`, retTypes: []Type{"bool"}, template: true},
+		"os.IsExist":                 {expr: `$(( {0} == 17 ))`, retTypes: []Type{"bool"}, template: true},
+		"fs.ModeDir":                 {expr: "2147483648", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModeAppend":              {expr: "1073741824", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModeExclusive":           {expr: "536870912", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModeTemporary":           {expr: "268435456", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModeSymlink":             {expr: "134217728", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModeDevice":              {expr: "67108864", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModeNamedPipe":           {expr: "33554432", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModeSocket":              {expr: "16777216", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModeSetuid":              {expr: "8388608", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModeSetgid":              {expr: "4194304", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModeCharDevice":          {expr: "2097152", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModeSticky":              {expr: "1048576", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModeIrregular":           {expr: "524288", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModeType":                {expr: "2401763328", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"fs.ModePerm":                {expr: "511", typ: "VALUE", retTypes: []Type{"fs.FileMode"}},
+		"time.Time.Unix":             {expr: `$(( {0} / 1000000000 ))`, retTypes: []Type{"int"}, template: true},
+		"time.Time.UnixMilli":        {expr: `$(( {0} / 1000000 ))`, retTypes: []Type{"int"}, template: true},
+		"time.Time.UnixNano":         {expr: `$(( {0} ))`, retTypes: []Type{"int"}, template: true},
+		"time.Time.UTC":              {expr: `"($(( {0} ))+0*0)"`, retTypes: []Type{"time.Time"}, template: true},
+		"time.Time.Local":            {expr: `$(( {0} ))`, retTypes: []Type{"time.Time"}, template: true},
+		"time.Time.Sub":              {expr: `$(( {0} - {1} ))`, retTypes: []Type{"time.Duration"}, template: true},
+		"time.Time.Before":           {expr: `$(( {0} < {1} ))`, retTypes: []Type{"bool"}, template: true},
+		"time.Time.After":            {expr: `$(( {0} > {1} ))`, retTypes: []Type{"bool"}, template: true},
+		"time.Time.Equal":            {expr: `$(( {0} == {1} ))`, retTypes: []Type{"bool"}, template: true},
+		"time.Unix":                  {expr: `$(( {0} * 1000000000 + {1} ))`, retTypes: []Type{"time.Time"}, template: true},
+		"time.UnixMilli":             {expr: `$(( {0} * 1000000 ))`, retTypes: []Type{"time.Time"}, template: true},
+		"time.Duration.Nanoseconds":  {expr: `{0}`, retTypes: []Type{"int"}, template: true},
+		"time.Duration.Microseconds": {expr: `$(( {0} / 1000 ))`, retTypes: []Type{"int"}, template: true},
+		"time.Duration.Milliseconds": {expr: `$(( {0} / 1000000 ))`, retTypes: []Type{"int"}, template: true},
+		"time.Duration.Seconds":      {typ: "FLOAT_EXPR", expr: "{0F} / 1000000000", retTypes: []Type{"float64"}, template: true},
+		"time.Nanosecond":            {expr: "1", typ: "VALUE", retTypes: []Type{"time.Duration"}},
+		"time.Microsecond":           {expr: "1000", typ: "VALUE", retTypes: []Type{"time.Duration"}},
+		"time.Millisecond":           {expr: "1000000", typ: "VALUE", retTypes: []Type{"time.Duration"}},
+		"time.Second":                {expr: "1000000000", typ: "VALUE", retTypes: []Type{"time.Duration"}},
+		"time.Minute":                {expr: "60000000000", typ: "VALUE", retTypes: []Type{"time.Duration"}},
+		"time.Hour":                  {expr: "3600000000000", typ: "VALUE", retTypes: []Type{"time.Duration"}},
+		"time.Layout":                {expr: `"01/02 03:04:05PM '06 -0700"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"time.ANSIC":                 {expr: `"Mon Jan _2 15:04:05 2006"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"time.UnixDate":              {expr: `"Mon Jan _2 15:04:05 MST 2006"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"time.RFC822":                {expr: `"02 Jan 06 15:04 MST"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"time.RFC1123":               {expr: `"Mon, 02 Jan 2006 15:04:05 MST"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"time.RFC1123Z":              {expr: `"Mon, 02 Jan 2006 15:04:05 -0700"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"time.RFC3339":               {expr: `"2006-01-02T15:04:05Z07:00"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"time.RFC3339Nano":           {expr: `"2006-01-02T15:04:05.999999999Z07:00"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"time.Kitchen":               {expr: `"3:04PM"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"time.Stamp":                 {expr: `"Jan _2 15:04:05"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"time.StampMilli":            {expr: `"Jan _2 15:04:05.000"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"time.DateTime":              {expr: `"2006-01-02 15:04:05"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"time.DateOnly":              {expr: `"2006-01-02"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"time.TimeOnly":              {expr: `"15:04:05"`, typ: "VALUE", retTypes: []Type{"string"}},
+		"os.Mkdir":                   {expr: "mkdir {0}", retTypes: []Type{"StatusCode"}, template: true},
+		"os.MkdirAll":                {expr: "mkdir -p {0}", retTypes: []Type{"StatusCode"}, template: true},
+		"os.Remove":                  {expr: "rm -f", retTypes: []Type{"StatusCode"}},
+		"os.RemoveAll":               {expr: "rm -rf", retTypes: []Type{"StatusCode"}},
+		"os.Rename":                  {expr: "mv", retTypes: []Type{"StatusCode"}},
+		"os.File.WriteString":        {expr: `echo -n {1} >&{0}`, template: true},
+		"os.File.Close":              {expr: `eval "exec {0}<&- {0}>&-"`, template: true},
+		"os.File.Fd":                 {expr: `{0}`, retTypes: []Type{"int"}, template: true},
+		// os/signal
+		"os.Interrupt":    {expr: "2", typ: "VALUE", retTypes: []Type{"syscall.Signal"}},
+		"os.Kill":         {expr: "9", typ: "VALUE", retTypes: []Type{"syscall.Signal"}},
+		"syscall.SIGHUP":  {expr: "1", typ: "VALUE", retTypes: []Type{"syscall.Signal"}},
+		"syscall.SIGINT":  {expr: "2", typ: "VALUE", retTypes: []Type{"syscall.Signal"}},
+		"syscall.SIGQUIT": {expr: "3", typ: "VALUE", retTypes: []Type{"syscall.Signal"}},
+		"syscall.SIGKILL": {expr: "9", typ: "VALUE", retTypes: []Type{"syscall.Signal"}},
+		"syscall.SIGUSR1": {expr: "10", typ: "VALUE", retTypes: []Type{"syscall.Signal"}},
+		"syscall.SIGUSR2": {expr: "12", typ: "VALUE", retTypes: []Type{"syscall.Signal"}},
+		"syscall.SIGPIPE": {expr: "13", typ: "VALUE", retTypes: []Type{"syscall.Signal"}},
+		"syscall.SIGALRM": {expr: "14", typ: "VALUE", retTypes: []Type{"syscall.Signal"}},
+		"syscall.SIGTERM": {expr: "15", typ: "VALUE", retTypes: []Type{"syscall.Signal"}},
+		"syscall.Kill":    {expr: "kill -{1} {0}", retTypes: []Type{"StatusCode"}, primaryIdx: -1, template: true},
+		"signal.Notify": {applyFunc: func(e *shExpression, arg []string) {
+			// the trap handler sends the signal number to the channel
+			var traps []string
+			for _, sig := range signalArgs(arg[1:]) {
+				traps = append(traps, `trap "printf '%s\n' `+sig+` >&`+arg[0]+`" `+sig)
+			}
+			e.expr = strings.Join(traps, "; ")
+		}},
+		"signal.Ignore": {applyFunc: func(e *shExpression, arg []string) {
+			e.expr = "trap '' " + strings.Join(signalArgs(arg), " ")
+		}},
+		"signal.Reset": {applyFunc: func(e *shExpression, arg []string) {
+			e.expr = "trap - " + strings.Join(signalArgs(arg), " ")
+		}},
+		// flag. flags are registered in GOTOSH_FLAG_*_N variables and parsed by the runtime
+		"flag.String":      {retTypes: []Type{"*string"}, primaryIdx: -1, applyFunc: defineFlag("s", false)},
+		"flag.Int":         {retTypes: []Type{"*int"}, primaryIdx: -1, applyFunc: defineFlag("i", false)},
+		"flag.Bool":        {retTypes: []Type{"*bool"}, primaryIdx: -1, applyFunc: defineFlag("b", false)},
+		"flag.Duration":    {retTypes: []Type{"*time.Duration"}, primaryIdx: -1, applyFunc: defineFlag("d", false)},
+		"flag.StringVar":   {applyFunc: defineFlag("s", true)},
+		"flag.IntVar":      {applyFunc: defineFlag("i", true)},
+		"flag.BoolVar":     {applyFunc: defineFlag("b", true)},
+		"flag.DurationVar": {applyFunc: defineFlag("d", true)},
+		"flag.Parse": {applyFunc: func(e *shExpression, arg []string) {
+			e.expr = s.useRuntime("flag.parse") + ` "$@"`
+		}},
+		"flag.CommandLine.Parse": {retTypes: []Type{"StatusCode"}, primaryIdx: -1, applyFunc: func(e *shExpression, arg []string) {
+			e.expr = s.useRuntime("flag.parse") + " " + strings.Join(arg, " ")
+		}},
+		"flag.CommandLine.SetOutput": {expr: "GOTOSH_FLAG_out={0}", template: true},
+		"flag.Args":                  {expr: `"${GOTOSH_FLAG_args[@]}"`, retTypes: []Type{"[]string"}},
+		"flag.NArg":                  {expr: `$GOTOSH_FLAG_nargs`, retTypes: []Type{"int"}},
+		// regexp. patterns are translated to POSIX ERE at compile time
+		"regexp.MustCompile": {retTypes: []Type{"regexp.Regexp"}, applyFunc: func(e *shExpression, arg []string) {
+			e.expr = s.compileRegexp(arg[0])
+		}},
+		// sort, slices and maps. slices are sorted in byte order like Go
+		"sort.Strings": {applyFunc2: sortInPlace("s")},
+		"sort.Ints":    {applyFunc2: sortInPlace("i")},
+		"slices.Sort":  {applyFunc2: sortInPlace("")},
+		"sort.Slice": {applyFunc2: func(e *shExpression, args []*shExpression) {
+			e.expr = s.useRuntime("sort.slice") + " " + varName(args[0].expr) + " " + strings.TrimSpace(args[1].AsValue())
+		}},
+		"sort.SliceStable": {applyFunc2: func(e *shExpression, args []*shExpression) {
+			e.expr = s.useRuntime("sort.slice") + " " + varName(args[0].expr) + " " + strings.TrimSpace(args[1].AsValue())
+		}},
+		"slices.Sorted": {retTypes: []Type{"[]any"}, primaryIdx: -1, applyFunc2: func(e *shExpression, args []*shExpression) {
+			pre, values := sliceArgs(args[0])
+			e.expr = pre + s.useRuntime("sort.sorted") + " " + sortKind(args[0].retTypes[0]) + " " + values
+			e.retTypes = args[0].retTypes
+		}},
+		"slices.Collect": {retTypes: []Type{"[]any"}, applyFunc2: func(e *shExpression, args []*shExpression) {
+			*e = *args[0]
+		}},
+		"slices.Index": {retTypes: []Type{"int"}, stdout: true, applyFunc2: func(e *shExpression, args []*shExpression) {
+			pre, values := sliceArgs(args[0])
+			e.expr = pre + s.useRuntime("slices.index") + " " + args[1].AsValue() + " " + values
+		}},
+		"slices.Contains": {retTypes: []Type{"bool"}, typ: "INT_EXPR", applyFunc2: func(e *shExpression, args []*shExpression) {
+			pre, values := sliceArgs(args[0])
+			e.expr = "$(" + pre + s.useRuntime("slices.index") + " " + args[1].AsValue() + " " + values + ") >= 0"
+		}},
+		"slices.Reverse": {applyFunc2: func(e *shExpression, args []*shExpression) {
+			name := varName(args[0].expr)
+			e.expr = s.useRuntime("slices.reverse") + ` "${` + name + `[@]}"; ` + name + `=("${` + RET_PREFIX + `0[@]}")`
+		}},
+		"slices.Compact": {retTypes: []Type{"[]any"}, primaryIdx: -1, applyFunc2: func(e *shExpression, args []*shExpression) {
+			pre, values := sliceArgs(args[0])
+			e.expr = pre + s.useRuntime("slices.compact") + " " + values
+			e.retTypes = args[0].retTypes
+		}},
+		"slices.Max": {retTypes: []Type{"any"}, primaryIdx: -1, applyFunc2: func(e *shExpression, args []*shExpression) {
+			pre, values := sliceArgs(args[0])
+			e.expr = pre + s.useRuntime("slices.extreme") + " max " + sortKind(args[0].retTypes[0]) + " " + values
+			e.retTypes = []Type{args[0].retTypes[0].ElementType()}
+		}},
+		"slices.Min": {retTypes: []Type{"any"}, primaryIdx: -1, applyFunc2: func(e *shExpression, args []*shExpression) {
+			pre, values := sliceArgs(args[0])
+			e.expr = pre + s.useRuntime("slices.extreme") + " min " + sortKind(args[0].retTypes[0]) + " " + values
+			e.retTypes = []Type{args[0].retTypes[0].ElementType()}
+		}},
+		"maps.Keys": {retTypes: []Type{"[]any"}, applyFunc2: func(e *shExpression, args []*shExpression) {
+			t := string(args[0].retTypes[0])
+			e.expr = `"${!` + varName(args[0].expr) + `[@]}"`
+			e.retTypes = []Type{Type("[]" + t[strings.Index(t, "[")+1:strings.Index(t, "]")])}
+		}},
+		// bufio
+		"bufio.NewScanner": {expr: "GOTOSH_RET_0__fd={0} GOTOSH_RET_0__split=0 GOTOSH_RET_0__text= GOTOSH_RET_0__rest= GOTOSH_RET_0__err=0", retTypes: []Type{"bufio.Scanner"}, primaryIdx: -1, template: true},
+		"bufio.ScanLines":  {expr: "0", typ: "VALUE", retTypes: []Type{"bufio.SplitFunc"}},
+		"bufio.ScanWords":  {expr: "1", typ: "VALUE", retTypes: []Type{"bufio.SplitFunc"}},
+		"bufio.Scanner.Scan": {typ: "CMD_STATUS", retTypes: []Type{"bool"}, applyFunc2: func(e *shExpression, args []*shExpression) {
+			e.expr = s.useRuntime("bufio.scan") + " " + varName(args[0].expr)
+		}},
+		"bufio.Scanner.Split": {expr: "{*0}__split={1}", template: true},
+		"bufio.Scanner.Text":  {expr: `"${{*0}__text}"`, retTypes: []Type{"string"}, template: true},
+		"bufio.Scanner.Err":   {expr: `${{*0}__err}`, retTypes: []Type{"error"}, template: true},
+		"bufio.NewReader":     {expr: "{0}", retTypes: []Type{"bufio.Reader"}, template: true},
+		"io.Discard":          {expr: "/dev/null", typ: "VALUE", retTypes: []Type{"io.Writer"}},
+		// the writers are separated by spaces and written in turn (see io.write)
+		"io.MultiWriter": {retTypes: []Type{"io.Writer"}, applyFunc: func(e *shExpression, arg []string) {
+			for i, w := range arg {
+				arg[i] = strings.Trim(w, `"`)
+			}
+			e.expr = `"` + strings.Join(arg, " ") + `"`
+		}},
+		"io.EOF": {expr: "1", typ: "VALUE", retTypes: []Type{"StatusCode"}},
+		// os/exec. argv is kept in the Args array and never evaluated by the shell
+		"exec.Command": {retTypes: []Type{"*exec.Cmd"}, primaryIdx: -1, applyFunc: func(e *shExpression, arg []string) {
+			e.expr = "GOTOSH_RET_0__Path=" + arg[0] + " GOTOSH_RET_0__Args=(" + strings.Join(arg, " ") + ") GOTOSH_RET_0__Env=() GOTOSH_RET_0__Dir=" +
+				" GOTOSH_RET_0__Stdin= GOTOSH_RET_0__Stdout= GOTOSH_RET_0__Stderr= GOTOSH_RET_0__Process__Pid=0"
+		}},
+		"exec.Cmd.Run":            {retTypes: []Type{"StatusCode"}, primaryIdx: -1, applyFunc2: runCmd("exec.run", "run")},
+		"exec.Cmd.Start":          {retTypes: []Type{"StatusCode"}, primaryIdx: -1, applyFunc2: runCmd("exec.run", "start")},
+		"exec.Cmd.Output":         {retTypes: []Type{"string", "StatusCode"}, primaryIdx: -1, applyFunc2: runCmd("exec.output", "output")},
+		"exec.Cmd.CombinedOutput": {retTypes: []Type{"string", "StatusCode"}, primaryIdx: -1, applyFunc2: runCmd("exec.output", "combined")},
+		"exec.Cmd.Wait":           {expr: "wait ${{*0}__Process__Pid}", retTypes: []Type{"StatusCode"}, primaryIdx: -1, template: true},
+		"os.Process.Kill":         {expr: "kill -KILL ${{*0}__Pid}", retTypes: []Type{"StatusCode"}, primaryIdx: -1, template: true},
+		"exec.ExitError.ExitCode": {expr: "{0}", retTypes: []Type{"int"}, template: true},
+		"exec.ExitError.Error":    {expr: `"exit status {0}"`, retTypes: []Type{"string"}, template: true},
+		"exec.ErrNotFound":        {expr: "127", typ: "VALUE", retTypes: []Type{"StatusCode"}},
+		// errors are exit statuses. callbacks can return these to control filepath.Walk
+		"errors.New":             {expr: "1", typ: "VALUE", retTypes: []Type{"StatusCode"}, template: true},
+		"fs.SkipDir":             {expr: "254", typ: "VALUE", retTypes: []Type{"StatusCode"}},
+		"fs.SkipAll":             {expr: "253", typ: "VALUE", retTypes: []Type{"StatusCode"}},
+		"filepath.SkipDir":       {expr: "254", typ: "VALUE", retTypes: []Type{"StatusCode"}},
+		"filepath.SkipAll":       {expr: "253", typ: "VALUE", retTypes: []Type{"StatusCode"}},
+		"filepath.ErrBadPattern": {expr: "1", typ: "VALUE", retTypes: []Type{"StatusCode"}},
+		"path.ErrBadPattern":     {expr: "1", typ: "VALUE", retTypes: []Type{"StatusCode"}},
+		"reflect.TypeOf":         {retTypes: []Type{"string"}, applyFunc: func(e *shExpression, arg []string) { e.expr = `"` + string(s.vars[varName(arg[0])].Type) + `"` }},
+		"runtime.Compiler":       {expr: "'gotosh'", typ: "VALUE", retTypes: []Type{"string"}},               // constant
+		"runtime.GOARCH":         {expr: "uname -m", typ: "VALUE", retTypes: []Type{"string"}, stdout: true}, // constant
+		"runtime.GOOS":           {expr: "uname -o", typ: "VALUE", retTypes: []Type{"string"}, stdout: true}, // constant
+		// math (float expressions, see floatExpr)
+		"math.Pi":        {expr: "3.141592653589793", typ: "VALUE", retTypes: []Type{"float64"}}, // constant
+		"math.E":         {expr: "2.718281828459045", typ: "VALUE", retTypes: []Type{"float64"}}, // constant
+		"math.MaxInt":    {expr: "9223372036854775807", typ: "VALUE", retTypes: []Type{"int"}},
+		"math.MinInt":    {expr: "-9223372036854775808", typ: "VALUE", retTypes: []Type{"int"}},
+		"math.MaxInt64":  {expr: "9223372036854775807", typ: "VALUE", retTypes: []Type{"int"}},
+		"math.MinInt64":  {expr: "-9223372036854775808", typ: "VALUE", retTypes: []Type{"int"}},
+		"math.MaxInt32":  {expr: "2147483647", typ: "VALUE", retTypes: []Type{"int"}},
+		"math.MinInt32":  {expr: "-2147483648", typ: "VALUE", retTypes: []Type{"int"}},
+		"math.MaxInt16":  {expr: "32767", typ: "VALUE", retTypes: []Type{"int"}},
+		"math.MinInt16":  {expr: "-32768", typ: "VALUE", retTypes: []Type{"int"}},
+		"math.MaxInt8":   {expr: "127", typ: "VALUE", retTypes: []Type{"int"}},
+		"math.MinInt8":   {expr: "-128", typ: "VALUE", retTypes: []Type{"int"}},
+		"math.MaxUint32": {expr: "4294967295", typ: "VALUE", retTypes: []Type{"int"}},
+		"math.MaxUint16": {expr: "65535", typ: "VALUE", retTypes: []Type{"int"}},
+		"math.MaxUint8":  {expr: "255", typ: "VALUE", retTypes: []Type{"int"}},
+		"math.Sqrt":      {typ: "FLOAT_EXPR", expr: "sqrt({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Pow":       {typ: "FLOAT_EXPR", expr: "go_pow({0F}, {1F})", retTypes: []Type{"float64"}, template: true},
+		"math.Exp":       {typ: "FLOAT_EXPR", expr: "e({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Log":       {typ: "FLOAT_EXPR", expr: "l({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Log2":      {typ: "FLOAT_EXPR", expr: "go_log2({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Log10":     {typ: "FLOAT_EXPR", expr: "go_log10({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Cbrt":      {typ: "FLOAT_EXPR", expr: "go_cbrt({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Sin":       {typ: "FLOAT_EXPR", expr: "s({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Cos":       {typ: "FLOAT_EXPR", expr: "c({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Tan":       {typ: "FLOAT_EXPR", expr: "go_tan({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Asin":      {typ: "FLOAT_EXPR", expr: "go_asin({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Acos":      {typ: "FLOAT_EXPR", expr: "go_acos({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Atan":      {typ: "FLOAT_EXPR", expr: "a({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Atan2":     {typ: "FLOAT_EXPR", expr: "go_atan2({0F}, {1F})", retTypes: []Type{"float64"}, template: true},
+		"math.Sinh":      {typ: "FLOAT_EXPR", expr: "go_sinh({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Cosh":      {typ: "FLOAT_EXPR", expr: "go_cosh({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Tanh":      {typ: "FLOAT_EXPR", expr: "go_tanh({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Floor":     {typ: "FLOAT_EXPR", expr: "go_floor({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Ceil":      {typ: "FLOAT_EXPR", expr: "go_ceil({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Round":     {typ: "FLOAT_EXPR", expr: "go_round({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Trunc":     {typ: "FLOAT_EXPR", expr: "go_trunc({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Abs":       {typ: "FLOAT_EXPR", expr: "go_abs({0F})", retTypes: []Type{"float64"}, template: true},
+		"math.Mod":       {typ: "FLOAT_EXPR", expr: "go_mod({0F}, {1F})", retTypes: []Type{"float64"}, template: true},
+		"math.Max":       {typ: "FLOAT_EXPR", expr: "go_max({0F}, {1F})", retTypes: []Type{"float64"}, template: true},
+		"math.Min":       {typ: "FLOAT_EXPR", expr: "go_min({0F}, {1F})", retTypes: []Type{"float64"}, template: true},
+		"math.Hypot":     {typ: "FLOAT_EXPR", expr: "sqrt(({0F})^2 + ({1F})^2)", retTypes: []Type{"float64"}, template: true},
+		// Inf and NaN are kept as strings. bc can't calculate with them.
+		"math.Inf": {applyFunc: func(e *shExpression, arg []string) {
+			if n, err := strconv.Atoi(arg[0]); err == nil {
+				e.expr = map[bool]string{true: "+Inf", false: "-Inf"}[n >= 0]
+			} else {
+				e.expr = `$([ ` + arg[0] + ` -ge 0 ] && echo +Inf || echo -Inf)`
+			}
+		}, retTypes: []Type{"float64"}},
+		"math.NaN":   {expr: "NaN", typ: "VALUE", retTypes: []Type{"float64"}},
+		"math.IsNaN": {typ: "CMD_STATUS", expr: `[ "{0}" = NaN ]`, retTypes: []Type{"bool"}, template: true},
+		"math.IsInf": {typ: "CMD_STATUS", expr: `{ [ "{0}" = +Inf ] && [ {1} -ge 0 ] || { [ "{0}" = -Inf ] && [ {1} -le 0 ]; }; }`, retTypes: []Type{"bool"}, template: true},
 		// TODO: cast
 		"int":              {expr: "printf '%.0f' {0}", retTypes: []Type{"int"}, stdout: true, template: true},
 		"byte":             {retTypes: []Type{"int"}},
+		"rune":             {retTypes: []Type{"int"}},
 		"float32":          {retTypes: []Type{"float32"}},
 		"float64":          {retTypes: []Type{"float64"}},
 		"string":           {retTypes: []Type{"string"}},
-		"strconv.Atoi":     {retTypes: []Type{"int", "StatusCode"}},
 		"strconv.Itoa":     {retTypes: []Type{"string"}},
 		"shell.StatusCode": {retTypes: []Type{"int"}},
+		"time.Duration":    {retTypes: []Type{"time.Duration"}},
+		"make": {applyFunc2: func(e *shExpression, args []*shExpression) {
+			e.retTypes = args[0].retTypes
+			if !s.IsType(e.retTypes[0], TYPE_CHAN) {
+				e.expr, e.values = "", []string{}
+				return
+			}
+			// a channel is a fifo opened for both reading and writing, like os.Pipe
+			e.expr = `_tmp=$(mktemp -d) && mkfifo $_tmp/f && ` + RET_PREFIX + `0=$(( GOTOSH_fd=${GOTOSH_fd:-2}+1 ))` +
+				` && eval "exec ${` + RET_PREFIX + `0}<>\"$_tmp/f\"" && rm -rf $_tmp`
+			e.primaryIdx = -1
+		}},
+		"panic": {applyFunc: func(e *shExpression, arg []string) {
+			// the ERR trap returns from the callers, but the caller may be in a condition where the trap doesn't run.
+			e.expr = s.useRuntime("panic.raise") + " " + strings.Join(arg, " ") + "; return 2"
+		}},
+		"recover": {typ: "VALUE", retTypes: []Type{"any"}, applyFunc: func(e *shExpression, arg []string) {
+			// panicking is stopped in the current shell, not in $(...)
+			s.pre = append(s.pre, s.useRuntime("panic.recover")+"; ")
+			e.expr = `"$GOTOSH_RECOVERED"`
+		}},
 		// slice
 		"len": {retTypes: []Type{"int"}, applyFunc2: func(e *shExpression, args []*shExpression) {
 			if len(args) > 0 && len(args[0].retTypes) > 0 {
@@ -148,10 +489,35 @@ var InitBuiltInFuncs = func(s *state) {
 				} else if s.IsType(args[0].retTypes[0], TYPE_MAP) {
 					e.expr = "${#" + varName(args[0].expr) + "[@]}"
 				} else {
-					e.expr = "${#" + strings.Trim(trimQuote(args[0].expr), "${}@") + "}"
+					e.expr = "${#" + strings.TrimSuffix(strings.Trim(trimQuote(args[0].expr), "${}@!"), ":-") + "}"
 				}
 			}
 		}},
-		"append": {retTypes: []Type{"[]any"}},
+		"append": {retTypes: []Type{"[]any"}, applyFunc2: func(e *shExpression, args []*shExpression) {
+			var values []string
+			for _, a := range args {
+				values = append(values, a.Values()...)
+			}
+			if len(args) > 0 && args[0].primaryIdx < 0 && args[0].expr != "" && s.IsType(args[0].retTypes[0], TYPE_ARRAY) {
+				// extend the slice returned in GOTOSH_RET_0 in place to keep the elements intact
+				e.expr = args[0].expr + "; " + args[0].RetVarName(0) + "+=(" + strings.Join(values[1:], " ") + ")"
+				e.retTypes = args[0].retTypes
+				e.primaryIdx = -1
+				return
+			}
+			e.expr = strings.Join(values, " ")
+		}},
+	}
+	for name, f := range s.funcs {
+		// os defines the same mode bits as io/fs
+		if mode, ok := strings.CutPrefix(name, "fs.Mode"); ok {
+			s.funcs["os.Mode"+mode] = f
+		}
+		// the shell package returns the exit status as shell.StatusCode
+		if strings.HasPrefix(name, "shell.") && slices.Contains(f.retTypes, "StatusCode") {
+			f.intStatus = true
+			s.funcs[name] = f
+		}
 	}
+	maps.Copy(s.funcs, s.fmtFuncs())
 }
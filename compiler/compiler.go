@@ -1,11 +1,14 @@
 package compiler
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"maps"
 	"os"
 	"path"
+	"slices"
 	"strings"
 	"text/scanner"
 )
@@ -34,7 +37,7 @@ func escapeShellString(s string) string {
 	if strings.Contains(s, "\\") {
 		return "$'" + strings.ReplaceAll(s[1:len(s)-1], "'", "\\'") + "'"
 	}
-	return strings.ReplaceAll(s, "$", "\\$")
+	return strings.NewReplacer("$", "\\$", "`", "\\`").Replace(s)
 }
 
 type Type string
@@ -45,6 +48,8 @@ func (t Type) ElementType() Type {
 		if p > 0 {
 			t = Type(string(t)[p+1:])
 		}
+	} else if strings.HasPrefix(string(t), TYPE_CHAN) {
+		t = t[len(TYPE_CHAN):]
 	}
 	return t
 }
@@ -54,12 +59,19 @@ type TypedName struct {
 	Type Type
 }
 
-var specialReturnTypes = map[Type]Type{"StatusCode": "int", "TempVarString": "string", "TempVarInt": "int"}
+// ReturnConvention is how functions return a single value: "stdout" (called as $(f), which forks a subshell)
+// or "var" (returned in GOTOSH_RET_0 like TempVarString, so the function can print and modify globals).
+var ReturnConvention = "stdout"
+
+var specialReturnTypes = map[Type]Type{"StatusCode": "int", "TempVarString": "string", "TempVarInt": "int", "TempVarBool": "bool"}
+
+// typeAliases maps types that share a representation.
+var typeAliases = map[string]string{"os.FileInfo": "fs.FileInfo", "os.DirEntry": "fs.DirEntry", "os.FileMode": "fs.FileMode", "os.Signal": "syscall.Signal", "*regexp.Regexp": "regexp.Regexp"}
 
 var asValueFunc = map[string]func(*shExpression) string{
-	"FLOAT_EXPR": func(e *shExpression) string { return `$(echo "` + e.expr + `" | bc -l)` },
 	"INT_EXPR":   func(e *shExpression) string { return "$(( " + e.expr + " ))" },
 	"STR_CMP":    func(e *shExpression) string { return "$([[ " + e.expr + " ]] && echo 1 || echo 0)" },
+	"CMD_STATUS": func(e *shExpression) string { return "$(" + e.expr + " && echo 1 || echo 0)" },
 }
 
 type shExpression struct {
@@ -72,16 +84,25 @@ type shExpression struct {
 	lhs        []string
 	declare    bool
 	values     []string // for array, slice, struct
+	pre        string   // commands to run before the expression (see hoist)
 	applyFunc  func(f *shExpression, arg []string)
 	applyFunc2 func(f *shExpression, arg []*shExpression)
 	template   bool
 	funcUsed   bool
+	panics     bool // calling the function may panic (see hoist)
+	intStatus  bool // the StatusCode is a shell.StatusCode, not an error (see varType)
+	inline     *inlineFunc
 }
 
 func (f *shExpression) AsValue() string {
 	expr := f.expr
 	if fn, ok := asValueFunc[f.typ]; ok {
 		expr = fn(f)
+	} else if len(f.retTypes) > 0 && f.primaryIdx < 0 && strings.HasPrefix(string(f.retTypes[0]), "[]") {
+		// the call is hoisted before the statement (see hoist) to keep the elements intact.
+		expr = `"${` + f.RetVarName(0) + `[@]}"`
+	} else if len(f.retTypes) > 0 && f.primaryIdx < 0 && f.retTypes[0] == "string" {
+		expr = "\"$(" + expr + " >&2; echo \"$" + f.RetVarName(0) + "\")\""
 	} else if len(f.retTypes) > 0 && f.primaryIdx < 0 {
 		expr = "$(" + expr + " >&2; echo \"$" + f.RetVarName(0) + "\")"
 	} else if f.stdout && len(f.retTypes) > 0 && (f.retTypes[0] == "int" || strings.HasPrefix(string(f.retTypes[0]), "[]")) {
@@ -92,6 +113,15 @@ func (f *shExpression) AsValue() string {
 	return expr
 }
 
+// AsCondition returns the condition for if/for statements.
+// CMD_STATUS expressions are executed directly to keep the side effects of the command.
+func (f *shExpression) AsCondition() string {
+	if f.typ == "CMD_STATUS" {
+		return f.expr
+	}
+	return "[ " + f.AsValue() + " -ne 0 ]"
+}
+
 func (f *shExpression) Values() []string {
 	if f.values != nil {
 		return f.values
@@ -99,6 +129,14 @@ func (f *shExpression) Values() []string {
 	return []string{f.AsValue()}
 }
 
+// varType returns the type of the variable to hold the i-th value. Errors are returned as StatusCode as well.
+func (f *shExpression) varType(i int) Type {
+	if f.retTypes[i] == "StatusCode" && !f.intStatus {
+		return "error"
+	}
+	return f.retTypes[i]
+}
+
 func (f *shExpression) RetVarName(i int) string {
 	if len(f.retTypes) > i && f.retTypes[i] == "StatusCode" {
 		return "?"
@@ -137,20 +175,43 @@ type state struct {
 	middleofline bool
 	skipNextScan bool
 	anonFuncID   int
+	tmpID        int
+	pre          []string
+	shortCircuit bool
+	panics       bool // the program calls panic() or is --checked
+	infNaN       bool // the program may calculate with Inf or NaN (see floatExpr)
+	src          string
+	chunk        *chunk
+	chunks       []*chunk
+	locals       map[string]bool
+	inlining     map[string]bool
+	hasDefer     bool
+	flags        []flagDef
+	errs         []error
+
+	floatPrecision int
 }
 
 func newState() *state {
 	var s state
 	s.w = os.Stdout
 	s.vars = map[string]TypedName{}
-	s.types = map[Type]Type{"*os.File": "int", "*exec.Cmd": "string", "bool": "int"} // Use fd as *os.File
+	s.types = map[Type]Type{"*os.File": "int", "strings.Builder": "string", "bool": "int", // Use fd as *os.File
+		"fs.FileInfo": "struct{:name:string:size:int:mode:fs.FileMode:modTime:time.Time:}", "fs.FileMode": "int", "fs.DirEntry": "string", "time.Time": "int", "time.Duration": "int", "syscall.Signal": "int", "regexp.Regexp": "string",
+		"bufio.Scanner": "struct{:fd:int:split:int:text:string:rest:string:err:error:}", "bufio.Reader": "int",
+		"io.Writer": "string", "io.Reader": "int", "*exec.ExitError": "int", "os.Process": "struct{:Pid:int:}",
+		"*exec.Cmd": "exec.Cmd", "exec.Cmd": "struct{:Path:string:Args:[]string:Env:[]string:Dir:string:Stdin:io.Reader:Stdout:io.Writer:Stderr:io.Writer:Process:os.Process:}"}
+	s.floatPrecision = -1
+	s.inlining = map[string]bool{}
 	InitBuiltInFuncs(&s)
+	asValueFunc["FLOAT_EXPR"] = func(e *shExpression) string { return s.floatExpr(e.expr) }
 	return &s
 }
 
 const TYPE_PTR string = "*"
 const TYPE_ARRAY string = "[]"
 const TYPE_MAP string = "map["
+const TYPE_CHAN string = "chan "
 
 func (s *state) IsType(t Type, prefix string) bool {
 	return strings.HasPrefix(string(s.resolveType(t)), prefix)
@@ -178,6 +239,11 @@ func (s *state) ScanToken(t rune) rune {
 	return s.lastToken
 }
 
+// errorf records an error which makes the compilation fail.
+func (s *state) errorf(format string, args ...any) {
+	s.errs = append(s.errs, fmt.Errorf("%s: "+format, append([]any{s.Position}, args...)...))
+}
+
 func (s *state) ScanIdent() string {
 	s.ScanToken(scanner.Ident)
 	return s.TokenText()
@@ -239,6 +305,16 @@ func (s *state) parseImportPkg() {
 		pkg := trimQuote(s.TokenText())
 		s.imports[path.Base(pkg)] = pkg
 	}
+	if s.imports["flag"] == "flag" && s.vars["flag.Usage"].Name == "" {
+		// flag keeps its state in globals. They must exist before any flag is defined since the script runs under -u.
+		s.setType("flag.Usage", funcType(nil, nil))
+		s.Writeln("GOTOSH_FLAG_n=0 GOTOSH_FLAG_nargs=0 GOTOSH_FLAG_out=2 flag__Usage=" + s.useRuntime("flag.usage"))
+	}
+	if s.imports["regexp"] == "regexp" && s.vars["GOTOSH_REGEXP_LC"].Name == "" {
+		// regexp matches in a UTF-8 locale whatever the locale of the script is. It's looked up once as matches may run in subshells.
+		s.setType("GOTOSH_REGEXP_LC", "string")
+		s.Writeln("GOTOSH_REGEXP_LC=$(" + s.useRuntime("regexp.locale") + ")")
+	}
 }
 
 func (s *state) parseImport() {
@@ -316,6 +392,8 @@ func (s *state) readType(scaned bool) Type {
 				}
 			}
 			return funcType(argTypes, retTypes)
+		} else if t == "chan" {
+			t += " " + string(s.readType(false))
 		} else if t == "struct" {
 			tok := s.ScanToken('{')
 			n := 0
@@ -350,6 +428,9 @@ func (s *state) readType(scaned bool) Type {
 	} else {
 		s.skipNextScan = true
 	}
+	if alias, ok := typeAliases[t]; ok {
+		t = alias
+	}
 	return Type(strings.TrimPrefix(t, "shell."))
 }
 func (s *state) setType(name string, t Type) TypedName {
@@ -357,6 +438,9 @@ func (s *state) setType(name string, t Type) TypedName {
 		t = special
 	}
 	s.vars[name] = TypedName{name, t}
+	if s.funcName != "" {
+		s.locals[name] = true
+	}
 	f := strings.Split(string(s.resolveType(Type(strings.TrimPrefix(string(t), "*")))), ":")
 	for i := 1; i < len(f)-2; i += 2 {
 		s.setType(name+"."+f[i], Type(f[i+1]))
@@ -383,13 +467,24 @@ func (s *state) resolveType(t Type) Type {
 	return t
 }
 
+// isIntType reports whether t is an integer type. All of them (and StatusCode/error) are handled as int.
+func (s *state) isIntType(t Type) bool {
+	switch s.resolveType(t) {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune", "StatusCode", "error":
+		return true
+	}
+	return false
+}
+
 func (s *state) readFuncCall(name string, invoke bool) *shExpression {
 	var args []*shExpression
 	if v, ok := s.vars[name]; ok && s.IsType(v.Type, "func(") {
+		s.useVar(name)
 		name = "$" + name // TODO: parse retTypes
 	} else if p := strings.LastIndex(name, "."); p >= 0 {
 		ns := name[:p]
 		if v, ok := s.vars[ns]; ok {
+			s.useVar(ns)
 			name = strings.TrimPrefix(string(v.Type), "*") + "." + name[p+1:]
 			var values []string
 			for _, field := range s.fields(v.Type, ns) {
@@ -400,26 +495,73 @@ func (s *state) readFuncCall(name string, invoke bool) *shExpression {
 			name = path.Base(pkg) + "." + name[p+1:]
 		}
 	}
-	if invoke {
+	var e *shExpression
+	if f, ok := s.funcs[name]; ok && invoke && args == nil && f.inline != nil {
+		e = s.inlineCall(name, f.inline)
+	}
+	if e == nil && invoke {
+		args = append(args, s.readCallArgs()...)
+	}
+	if e == nil {
+		e = s.callFunc(name, args, invoke)
+	}
+	for invoke && s.Peek() == '.' && len(e.retTypes) > 0 && s.hasMethods(e.retTypes[0]) {
+		// method call on the returned value. e.g. st.ModTime().Unix()
 		s.Scan()
-		for s.lastToken != scanner.EOF && s.lastToken != ')' {
-			args = append(args, s.readExpression("", ",)", false))
+		name = strings.TrimPrefix(string(e.retTypes[0]), "*") + "." + s.ScanIdent()
+		e = s.callFunc(name, append([]*shExpression{e}, s.readCallArgs()...), true)
+	}
+	return e
+}
+
+// typeAssert returns x.(typ) and whether it succeeded. Values are not typed at runtime, so it always succeeds
+// except for error types: errors are exit statuses and the assertion succeeds only when the status is not 0.
+func (s *state) typeAssert(name string, typ Type) *shExpression {
+	value := varValue(varName(name))
+	ok := "1"
+	if strings.HasSuffix(string(typ), "Error") {
+		ok = "$(( " + value + " != 0 ))"
+	}
+	return &shExpression{expr: RET_PREFIX + "0=" + value + " " + RET_PREFIX + "1=" + ok, retTypes: []Type{typ, "bool"}, primaryIdx: -1}
+}
+
+func (s *state) hasMethods(t Type) bool {
+	prefix := strings.TrimPrefix(string(t), "*") + "."
+	for name := range s.funcs {
+		if strings.HasPrefix(name, prefix) {
+			return true
 		}
 	}
+	return false
+}
+
+func (s *state) readCallArgs() (args []*shExpression) {
+	s.Scan()
+	for s.lastToken != scanner.EOF && s.lastToken != ')' {
+		args = append(args, s.hoist(s.readExpression("", ",)", false)))
+	}
+	return
+}
 
+func (s *state) callFunc(name string, args []*shExpression, invoke bool) *shExpression {
 	expr := strings.ReplaceAll(name, ".", "__")
 	f, ok := s.funcs[name]
+	if invoke && s.chunk != nil {
+		s.chunk.calls = true
+	}
 	if ok {
 		f.funcUsed = true
 		s.funcs[name] = f
+		s.use(f.expr)
 		if f.typ != "VALUE" && !invoke {
 			return &shExpression{expr: expr, retTypes: []Type{funcType(f.argTypes, f.retTypes)}}
 		}
 		expr = f.expr
 	} else {
 		f.retTypes = []Type{""}
+		f.panics = s.panics // a function value or a function declared later
 	}
-	e := &shExpression{expr: expr, typ: f.typ, retTypes: f.retTypes, primaryIdx: f.primaryIdx, stdout: f.stdout}
+	e := &shExpression{expr: expr, typ: f.typ, retTypes: f.retTypes, primaryIdx: f.primaryIdx, stdout: f.stdout, panics: f.panics && invoke, intStatus: f.intStatus}
 
 	if f.applyFunc2 != nil {
 		f.applyFunc2(e, args)
@@ -465,7 +607,7 @@ func (s *state) readValues() (values []string) {
 		end = '}'
 	}
 	for s.lastToken != scanner.EOF && s.lastToken != end {
-		values = append(values, s.readExpression("", string(end)+":", false).Values()...)
+		values = append(values, s.hoist(s.readExpression("", string(end)+":", false)).Values()...)
 	}
 	return
 }
@@ -475,11 +617,22 @@ func (s *state) readExpression(typeHint Type, endToks string, allowAssign bool)
 	l := s.Line
 	tokens := 0
 	declare := false
-	var lastExpr *shExpression
+	var lastExpr, hoisted *shExpression
 	var lastVar string
 	var expressionType Type = "int"
 	var lhs, lhs_candidate, values []string
 	var lastTok rune
+	divisor := false
+	var and, or *shortCircuit
+	andStart := 0
+	// & | ^ bind tighter than comparisons in Go, but not in the shell: the operands are parenthesized.
+	cmpStart, cmpRight, bitwise := 0, -1, false
+	parenBitwise := func(start int) {
+		if bitwise {
+			expr = expr[:start] + "(" + expr[start:] + ")"
+		}
+		bitwise = false
+	}
 	for tok := s.Scan(); tok != scanner.EOF && (endToks != "" || strings.ContainsRune(".=*/%,:", lastTok) || s.Line == l); tok = s.Scan() {
 		t := s.TokenText()
 		l = s.Line
@@ -489,16 +642,20 @@ func (s *state) readExpression(typeHint Type, endToks string, allowAssign bool)
 		} else if strings.ContainsRune(endToks, tok) || (!allowAssign && tok == ',') || tok == ';' {
 			break
 		} else if tok == '(' {
-			lastExpr = s.readExpression("", ")", false)
+			lastExpr = s.hoist(s.readExpression("", ")", false))
 			if expressionType != "string" && (lastExpr.typ == "INT_EXPR" || lastExpr.typ == "FLOAT_EXPR") {
 				t = "(" + lastExpr.expr + ")"
 			} else {
 				t = lastExpr.AsValue()
 			}
+			if len(lastExpr.retTypes) > 0 && lastExpr.retTypes[0] == "string" {
+				expressionType = "string"
+			}
+			tok = ')' // (a+b)*c is not a dereference
 		} else if tok == scanner.Int {
 			t = strings.Replace(strings.Replace(t, "0o", "8#", 1), "0b", "2#", 1)
 		} else if tok == scanner.Float {
-			expressionType = "float32"
+			expressionType = "float64"
 		} else if tok == scanner.String {
 			expressionType = "string"
 			t = escapeShellString(t)
@@ -511,11 +668,31 @@ func (s *state) readExpression(typeHint Type, endToks string, allowAssign bool)
 			expressionType = lastExpr.retTypes[0]
 		} else if tok == scanner.Ident && t == "range" {
 			t = "#RANGE#"
-		} else if tok == '[' || tok == scanner.Ident && (t == "struct" || t == "map") { // type
+		} else if tok == '[' || tok == scanner.Ident && (t == "struct" || t == "map" || t == "chan") { // type
 			typeHint = s.readType(true)
-			values = s.readValues()
+			if tok := s.PeekToken(); tok == '{' || tok == '(' {
+				values = s.readValues()
+			}
 			t = ""
-		} else if tok == scanner.Ident || ((tok == '*' || tok == '&') && strings.ContainsRune("=+-*/([\x00", lastTok)) {
+		} else if tok == '<' && s.Peek() == '-' {
+			s.Scan()
+			if lastVar == "" || expr != lastVar {
+				// receive: <-ch
+				ch := s.ScanIdent()
+				for s.Peek() == '.' {
+					s.Scan()
+					ch += "." + s.ScanIdent()
+				}
+				s.useVar(ch)
+				lastExpr = &shExpression{expr: s.useRuntime("chan.recv") + " " + varValue(varName(ch)), retTypes: []Type{s.vars[ch].Type.ElementType()}, primaryIdx: -1}
+				t = lastExpr.AsValue()
+				expressionType = lastExpr.retTypes[0]
+			} else {
+				// send: ch <- v
+				v := s.hoist(s.readExpression("", endToks, false))
+				return &shExpression{expr: "printf '%s\\n' " + strings.Join(v.Values(), " ") + " >&" + varValue(lastVar)}
+			}
+		} else if tok == scanner.Ident || (tok == '*' && strings.ContainsRune("=+-*/%<>!&|([\x00", lastTok)) || (tok == '&' && strings.ContainsRune("=+-*/([\x00", lastTok)) {
 			derefPtr := tok == '*'
 			refPtr := tok == '&'
 			tok = scanner.Ident
@@ -523,11 +700,17 @@ func (s *state) readExpression(typeHint Type, endToks string, allowAssign bool)
 				s.Scan()
 			}
 			t = s.TokenText()
+			var assertType Type
 			for tok := s.Scan(); tok == '.'; tok = s.Scan() {
-				if s.Scan() == '.' {
+				if tok := s.Scan(); tok == '.' {
 					s.ScanToken('.')
 					s.ScanToken('.')
 					break
+				} else if tok == '(' {
+					assertType = s.readType(false)
+					s.ScanToken(')')
+					s.Scan()
+					break
 				}
 				t += "." + s.TokenText()
 			}
@@ -536,9 +719,14 @@ func (s *state) readExpression(typeHint Type, endToks string, allowAssign bool)
 				t = s.packageName + "." + t
 			}
 			if vt, ok := s.vars[t]; ok {
+				s.useVar(t)
 				expressionType = vt.Type
+				if expressionType == "any" {
+					expressionType = "string" // e.g. the value of recover(), nil is an empty string
+				}
 				if derefPtr {
 					expressionType = Type(strings.TrimPrefix(string(expressionType), "*"))
+					s.check("panic.nil", varName(t))
 				}
 			}
 			ot := t
@@ -552,8 +740,9 @@ func (s *state) readExpression(typeHint Type, endToks string, allowAssign bool)
 				s.Scan()
 				var idx []*shExpression
 				for s.lastToken != scanner.EOF && s.lastToken != ']' {
-					idx = append(idx, s.readExpression("int", ":]", false))
+					idx = append(idx, s.hoist(s.readExpression("int", ":]", false)))
 				}
+				s.checkIndex(ot, expressionType, idx)
 				if len(idx) == 1 && expressionType != "string" {
 					t = ot + "[" + idx[0].AsValue() + "]:-"
 					expressionType = expressionType.ElementType()
@@ -565,7 +754,11 @@ func (s *state) readExpression(typeHint Type, endToks string, allowAssign bool)
 				lt = strings.TrimSuffix(t, ":-")
 			}
 
-			if _, ok := s.types[Type(ot)]; ok {
+			if assertType != "" {
+				lastExpr = s.typeAssert(ot, assertType)
+				t = lastExpr.AsValue()
+				expressionType = assertType
+			} else if _, ok := s.types[Type(ot)]; ok && s.funcs[ot].retTypes == nil {
 				if tok := s.PeekToken(); tok == '{' || tok == '(' {
 					values = s.readValues()
 					typeHint = Type(ot)
@@ -573,14 +766,21 @@ func (s *state) readExpression(typeHint Type, endToks string, allowAssign bool)
 				t = ""
 			} else if _, ok := s.vars[ot]; !ok || s.lastToken == '(' {
 				lastExpr = s.readFuncCall(ot, s.lastToken == '(')
-				t = lastExpr.AsValue()
-				if len(lastExpr.retTypes) > 0 && lastExpr.retTypes[0] != "" {
+				hoisted = s.hoist(lastExpr)
+				t = hoisted.AsValue()
+				if len(lastExpr.retTypes) > 0 && lastExpr.retTypes[0] == "any" {
+					expressionType = "string"
+				} else if len(lastExpr.retTypes) > 0 && lastExpr.retTypes[0] != "" {
 					expressionType = lastExpr.retTypes[0]
+				} else if ot == "nil" && expressionType == "string" {
+					t = `""`
 				}
 			} else if expressionType == "float32" || expressionType == "float64" {
 				t = " " + varValue(t) + " "
-			} else if expressionType == "string" || s.IsType(expressionType, TYPE_ARRAY) {
+			} else if s.resolveType(expressionType) == "string" || s.IsType(expressionType, TYPE_ARRAY) {
 				t = "\"" + varValue(t) + "\""
+			} else if strings.HasSuffix(t, "]:-") {
+				t = varValue(t + "0") // element of a numeric slice or map
 			}
 			if refPtr {
 				t = "\"" + varName(t) + "\""
@@ -594,6 +794,8 @@ func (s *state) readExpression(typeHint Type, endToks string, allowAssign bool)
 			s.Scan()
 			t = " " + t + "= "
 			typeHint = "bool"
+			parenBitwise(cmpStart)
+			cmpRight = len(expr) + len(t)
 		} else if tok == ':' && s.Peek() == '=' {
 			declare = true
 			t = ""
@@ -611,25 +813,77 @@ func (s *state) readExpression(typeHint Type, endToks string, allowAssign bool)
 			t = ""
 			expr = ""
 			tokens = -1
+		} else if (tok == '&' || tok == '|') && s.Peek() == tok {
+			if cmpRight >= 0 {
+				parenBitwise(cmpRight)
+			}
+			s.shortCircuit = true
+			expr = s.foldShortCircuit(expr, and, expressionType)
+			and = nil
+			if tok == '|' {
+				expr = s.foldShortCircuit(expr, or, expressionType)
+				or = &shortCircuit{op: "||", pos: len(expr), pre: len(s.pre), leftType: expressionType}
+				andStart = len(expr) + 2
+			} else {
+				and = &shortCircuit{op: "&&", start: andStart, pos: len(expr), pre: len(s.pre), leftType: expressionType}
+			}
+			cmpStart, cmpRight, bitwise = len(expr)+2, -1, false
+		} else if (tok == '<' || tok == '>') && lastTok != tok && s.Peek() != tok {
+			typeHint = "bool"
+			parenBitwise(cmpStart)
+			cmpRight = len(expr) + len(t)
 		} else if tok == '.' || tok == '+' && expressionType == "string" || tok == '=' && expr == "" {
 			t = "" // skip
+		} else if (tok == '&' || tok == '|' || tok == '^') && lastTok != tok {
+			bitwise = true
 		}
 		expr += t
+		if divisor && tok != '-' && tok != '+' {
+			// the divisor evaluated twice must not call a function
+			if tok != scanner.Int && s.isIntType(expressionType) && !strings.Contains(t, "$(") {
+				s.check("panic.div", "$(( "+t+" ))")
+			}
+			divisor = false
+		}
+		divisor = divisor || tok == '/' || tok == '%'
 		tokens++
 		lastTok = tok
 		if !s.skipNextScan {
 			l = s.Line
 		}
 	}
+	if cmpRight >= 0 {
+		parenBitwise(cmpRight)
+	}
+	expr = s.foldShortCircuit(s.foldShortCircuit(expr, and, expressionType), or, expressionType)
 	if typeHint == "" {
 		typeHint = expressionType
 	}
 	s.skipNextScan = s.skipNextScan || s.Line != l
+	if hoisted != nil && hoisted != lastExpr && (expr == hoisted.AsValue() || lastExpr.primaryIdx < 0 && expr == "#RANGE#"+hoisted.AsValue()) {
+		// the call is the whole expression: the caller reads the returned values.
+		s.pre = s.pre[:len(s.pre)-1]
+		s.tmpID--
+		lastExpr.lhs = lhs
+		lastExpr.declare = declare
+		if strings.HasPrefix(expr, "#RANGE#") {
+			lastExpr.expr = "#RANGE#" + lastExpr.expr
+		}
+		return lastExpr
+	}
 	e := &shExpression{expr: strings.TrimSpace(expr), retTypes: []Type{typeHint}, declare: declare, lhs: lhs, values: values}
-	if lastExpr != nil && (expr == lastExpr.expr || expr == lastExpr.AsValue()) {
+	if lastExpr != nil && (expr == lastExpr.expr || expr == lastExpr.AsValue() || expr == "("+lastExpr.expr+")") {
 		lastExpr.lhs = e.lhs
 		lastExpr.declare = e.declare
 		return lastExpr
+	} else if lastExpr != nil && lastExpr.primaryIdx < 0 && expr == "#RANGE#"+lastExpr.AsValue() {
+		lastExpr.lhs = e.lhs
+		lastExpr.declare = e.declare
+		lastExpr.expr = "#RANGE#" + lastExpr.expr
+		return lastExpr
+	} else if lastExpr != nil && lastExpr.typ == "CMD_STATUS" && expr == "!"+lastExpr.AsValue() {
+		e.expr = "! " + lastExpr.expr
+		e.typ = lastExpr.typ
 	} else if lastVar != "" && expr == lastVar && !s.IsType(typeHint, TYPE_MAP) {
 		if s.IsType(typeHint, TYPE_PTR) {
 			expr = "!" + expr // bash: !, zsh: (!)
@@ -645,24 +899,168 @@ func (s *state) readExpression(typeHint Type, endToks string, allowAssign bool)
 		e.typ = "STR_CMP"
 	} else if tokens > 1 && (expressionType == "float32" || expressionType == "float64") {
 		e.typ = "FLOAT_EXPR"
-	} else if tokens > 1 && s.resolveType(expressionType) == "int" && !s.IsType(expressionType, TYPE_ARRAY) {
+	} else if tokens > 1 && s.isIntType(expressionType) {
 		e.typ = "INT_EXPR"
 	}
 	return e
 }
 
+// shortCircuit is && or || in an expression. The left operand is expr[start:pos].
+type shortCircuit struct {
+	op       string
+	start    int
+	pos      int
+	pre      int // len(s.pre) at the operator
+	leftType Type
+}
+
+// condition returns the command testing the expression x of type t.
+func condition(x string, t Type) string {
+	if t == "string" {
+		return "[[ " + strings.TrimSpace(x) + " ]]"
+	}
+	return "(( " + strings.TrimSpace(x) + " ))"
+}
+
+// foldShortCircuit runs the commands hoisted from the right operand of op only if the left operand doesn't decide
+// the result, e.g. a || f() is "if ! a; then f; tmp=...; fi". The operation is replaced with the saved result.
+func (s *state) foldShortCircuit(expr string, op *shortCircuit, rightType Type) string {
+	if op == nil || len(s.pre) == op.pre {
+		return expr
+	}
+	tmp, decl := s.tempVar()
+	left, right := condition(expr[op.start:op.pos], op.leftType), condition(expr[op.pos+2:], rightType)
+	guarded := strings.Join(s.pre[op.pre:], "")
+	s.pre = s.pre[:op.pre]
+	if op.op == "&&" {
+		s.pre = append(s.pre, decl+"=0; if "+left+"; then "+guarded+right+" && "+tmp+"=1; fi; ")
+	} else {
+		s.pre = append(s.pre, decl+"=1; if ! "+left+"; then "+guarded+right+" || "+tmp+"=0; fi; ")
+	}
+	return expr[:op.start] + varValue(tmp) + " == 1 "
+}
+
+// tempVar returns a new variable to save a value in the statement and its declaration, which is local in functions.
+func (s *state) tempVar() (string, string) {
+	tmp := fmt.Sprintf("GOTOSH_TMP_%d", s.tmpID)
+	s.tmpID++
+	if s.funcName != "" {
+		return tmp, "local " + tmp
+	}
+	return tmp, tmp
+}
+
+// hoist calls the function returning its value in GOTOSH_RET_0 before the statement and returns the saved value.
+// The value is saved in a local variable since the next call in the same statement may overwrite GOTOSH_RET_0.
+// Calls after && or || are hoisted as well, including the ones writing the value to stdout, and run only if
+// the left operand doesn't decide the result (see foldShortCircuit).
+// If the program may panic, the functions are called before the statement to return while panicking, as the
+// status of $(f) in the arguments is lost and the ERR trap doesn't run in conditions.
+// Float expressions evaluated by the bc coprocess are hoisted as well to avoid a subshell for each expression.
+func (s *state) hoist(e *shExpression) *shExpression {
+	if e.typ == "FLOAT_EXPR" && FloatBackend == "coproc" {
+		tmp, decl := s.tempVar()
+		s.pre = append(s.pre, s.floatCall(e.expr)+"; "+decl+`="$`+RET_PREFIX+`0"; `)
+		return &shExpression{expr: varValue(tmp), retTypes: e.retTypes}
+	}
+	if (s.shortCircuit || e.panics) && e.typ == "" && e.stdout && e.primaryIdx == 0 && len(e.retTypes) == 1 {
+		tmp, decl := s.tempVar()
+		v := &shExpression{expr: varValue(tmp), retTypes: e.retTypes}
+		value := e.AsValue()
+		if s.IsType(e.retTypes[0], TYPE_ARRAY) {
+			value = "(" + value + ")"
+			v.expr = `"${` + tmp + `[@]}"`
+		} else if t := e.retTypes[0]; t == "string" || specialReturnTypes[t] == "string" {
+			v.expr = `"` + v.expr + `"`
+		}
+		if !e.panics {
+			s.pre = append(s.pre, decl+"="+value+"; ")
+		} else if decl != tmp {
+			s.pre = append(s.pre, decl+"; "+tmp+"="+value+s.unwind())
+		} else {
+			s.pre = append(s.pre, tmp+"="+value+s.unwind())
+		}
+		return v
+	}
+	if e.typ != "" || e.stdout || e.primaryIdx >= 0 ||
+		len(e.retTypes) != 1 || e.retTypes[0] == "StatusCode" || len(s.fields(e.retTypes[0], "")) != 1 {
+		return e
+	}
+	tmp, decl := s.tempVar()
+	v := &shExpression{expr: varValue(tmp), retTypes: e.retTypes}
+	call := e.expr + "; "
+	if e.panics {
+		call = e.expr + s.unwind()
+	}
+	if s.IsType(e.retTypes[0], TYPE_ARRAY) {
+		s.pre = append(s.pre, call+decl+`=("${`+e.RetVarName(0)+`[@]}"); `)
+		v.expr = `"${` + tmp + `[@]}"`
+	} else {
+		s.pre = append(s.pre, call+decl+`="$`+e.RetVarName(0)+`"; `)
+		if t := e.retTypes[0]; t == "string" || specialReturnTypes[t] == "string" {
+			v.expr = `"` + v.expr + `"`
+		}
+	}
+	return v
+}
+
+// unwind returns the command returning from the function if the preceding call failed while panicking (see panic.unwind).
+func (s *state) unwind() string {
+	return " || if " + s.useRuntime("panic.unwind") + "; then return 2; fi; "
+}
+
+// takePre returns the hoisted commands to run before the current statement.
+func (s *state) takePre() string {
+	pre := strings.Join(s.pre, "")
+	s.pre = nil
+	s.shortCircuit = false
+	return pre
+}
+
 func (s *state) writeExpr(e *shExpression, typ Type) {
+	if e.typ == "FLOAT_EXPR" && FloatBackend == "coproc" {
+		// e is not modified as the post statement of a for loop is written more than once
+		v := *e
+		v.expr, v.typ = s.hoist(e).expr, ""
+		e = &v
+	}
+	if pre := e.pre + s.takePre(); pre != "" {
+		s.WriteString(pre)
+	}
 	statusIndex := -1
 	for i, name := range e.lhs {
 		if name != "_" && e.RetVarName(i) == "?" {
 			statusIndex = i
 		}
 	}
+	// the status is saved in the command of the call before checking whether it panicked (see unwind).
+	// With --debug, the errors returned by the call are not reported by the ERR trap (see debug.err),
+	// even if they are ignored, e.g. f, _ := os.Open(name).
+	status, suffix := "", ""
+	if e.panics {
+		suffix = strings.TrimSuffix(s.unwind(), "; ")
+	} else if Debug && statusIndex < 0 && slices.Contains(e.retTypes, "StatusCode") && !e.intStatus {
+		suffix = " || :"
+	}
+	if statusIndex >= 0 && (e.panics || Debug) {
+		status = varName(e.lhs[statusIndex])
+		if e.declare {
+			s.setType(e.lhs[statusIndex], e.varType(statusIndex))
+		}
+		if e.declare && s.funcName != "" {
+			s.Writeln("local " + status + "=")
+		}
+		if e.panics {
+			suffix = " && " + status + "=0 || { " + status + "=$?; if " + s.useRuntime("panic.unwind") + "; then return 2; fi; }"
+		} else {
+			suffix = " && " + status + "=0 || " + status + "=$?"
+		}
+	}
 	writeAssign := func(i int, v, vn string) {
 		if typ != "" {
 			s.setType(e.lhs[i], typ)
 		} else if e.declare && len(e.retTypes) > i {
-			s.setType(e.lhs[i], e.retTypes[i])
+			s.setType(e.lhs[i], e.varType(i))
 		}
 		local := e.declare && s.funcName != ""
 		for vi, field := range s.fields(s.vars[e.lhs[i]].Type, "") {
@@ -676,7 +1074,9 @@ func (s *state) writeExpr(e *shExpression, typ Type) {
 			} else if local {
 				s.WriteString("local ")
 			}
-			if vn != "" && len(e.retTypes) > i {
+			if vn != "" && len(e.retTypes) > i && s.IsType(field.Type, TYPE_ARRAY) {
+				s.Writeln(name + "=(\"${" + varName(vn+field.Name) + "[@]}\")")
+			} else if vn != "" && len(e.retTypes) > i {
 				s.Writeln(name + "=\"$" + varName(vn+field.Name) + "\"")
 			} else if local || v != "" || len(e.values) > vi {
 				tv := v
@@ -684,12 +1084,15 @@ func (s *state) writeExpr(e *shExpression, typ Type) {
 					tv = "(" + strings.Join(e.Values(), " ") + ")"
 				} else if len(e.values) > vi {
 					tv = e.values[vi]
-				} else if tv == "" && field.Type == "int" {
+				} else if tv == "" && (s.isIntType(field.Type) || field.Type == "float32" || field.Type == "float64") {
 					tv = "0"
 				}
-				if local && statusIndex >= 0 {
+				if local && (statusIndex >= 0 || e.panics) {
 					s.Writeln(name + "=") // to avoid 'local' modify status code
 				}
+				if suffix != "" && i == e.primaryIdx && (e.stdout || status != "") {
+					tv += suffix
+				}
 				s.Writeln(name + "=" + tv)
 			}
 		}
@@ -697,9 +1100,9 @@ func (s *state) writeExpr(e *shExpression, typ Type) {
 	if v := e.AsValue(); e.primaryIdx >= 0 && len(e.lhs) > e.primaryIdx {
 		writeAssign(e.primaryIdx, v, "")
 	} else if v != "" {
-		s.Writeln(e.AsExec())
+		s.Writeln(e.AsExec() + suffix)
 	}
-	if statusIndex >= 0 {
+	if statusIndex >= 0 && status == "" {
 		writeAssign(statusIndex, "", "?")
 	}
 	for i, name := range e.lhs {
@@ -716,6 +1119,9 @@ func (s *state) procVar(names []string) {
 	}
 	for ; len(names) == 0 || s.lastToken == ','; s.Scan() {
 		names = append(names, prefix+s.ScanIdent())
+		if len(s.cl) == 0 {
+			s.declare("$" + names[len(names)-1])
+		}
 	}
 	var typ = s.readType(true)
 	e := &shExpression{}
@@ -730,25 +1136,34 @@ func (s *state) procVar(names []string) {
 func (s *state) procReturn() {
 	f := s.funcs[s.funcName]
 	var status *shExpression
+	ret := ""
 	for i, t := range f.retTypes {
 		e := s.readExpression("", "", false)
-		values := e.Values()
-		if i == 0 && len(e.retTypes) == len(f.retTypes) && (e.primaryIdx < 0 || e.stdout) {
-			s.Writeln(e.expr + "; return $?")
+		if i == 0 && len(e.retTypes) == len(f.retTypes) && (e.primaryIdx < 0 && !f.stdout || e.stdout && f.stdout) {
+			s.Writeln(s.takePre() + e.expr + "; return $?")
 			return
-		} else if t == "StatusCode" {
+		}
+		e = s.hoist(e)
+		values := e.Values()
+		if t == "StatusCode" {
 			status = e
 		} else if i == f.primaryIdx {
-			s.WriteString("echo " + strings.Join(values, " ") + "; ")
+			ret += "echo " + strings.Join(values, " ") + "; "
+		} else if s.IsType(t, TYPE_ARRAY) {
+			ret += f.RetVarName(i) + "=(" + strings.Join(values, " ") + "); "
 		} else if fields := s.fields(t, f.RetVarName(i)); len(values) >= len(fields) {
 			for vi, field := range fields {
-				s.WriteString(varName(field.Name) + "=" + values[vi] + "; ")
+				ret += varName(field.Name) + "=" + values[vi] + "; "
 			}
 		}
 		if s.lastToken != ',' {
 			break
 		}
 	}
+	// the values are set after the hoisted calls which may overwrite GOTOSH_RET_n
+	if ret = s.takePre() + ret; ret != "" {
+		s.WriteString(ret)
+	}
 	if status != nil {
 		s.Writeln("return " + status.AsValue())
 	} else {
@@ -756,30 +1171,54 @@ func (s *state) procReturn() {
 	}
 }
 
+// setReturnConvention decides how the function returns its values.
+// A single simple value (optionally paired with a StatusCode) is written to stdout, others are returned in GOTOSH_RET_n variables.
+func (s *state) setReturnConvention(f *shExpression) {
+	f.primaryIdx = -1
+	f.stdout = false
+	if len(f.retTypes) == 1 || len(f.retTypes) == 2 && (f.retTypes[0] == "StatusCode" || f.retTypes[1] == "StatusCode") {
+		for i, t := range f.retTypes {
+			if _, ok := specialReturnTypes[t]; !ok && len(s.fields(t, "")) == 1 && !s.IsType(t, TYPE_PTR) {
+				f.primaryIdx = i
+				f.stdout = true
+			}
+		}
+	}
+}
+
 func (s *state) compileFunc(name, shname string, args []string, argTypes []Type) shExpression {
 	previousFuncName := s.funcName
 	previousVars := maps.Clone(s.vars)
+	previousLocals := s.locals
+	s.locals = map[string]bool{}
+	maps.Copy(s.locals, previousLocals)
 	s.funcName = name
 	for i, n := range args {
 		argTypes[i] = Type(strings.Replace(string(argTypes[i]), "...", "[]", 1))
 		s.setType(n, argTypes[i])
 	}
 
-	f := shExpression{expr: shname, primaryIdx: -1, argTypes: argTypes}
+	f := shExpression{expr: shname, primaryIdx: -1, argTypes: argTypes, panics: s.panics}
 	if s.PeekToken() == '(' {
 		_, f.retTypes = s.readFuncArgs(nil, nil)
 	} else if typ := s.readType(false); typ != "" {
 		f.retTypes = []Type{typ}
 	}
-	if len(f.retTypes) == 1 || len(f.retTypes) == 2 && (f.retTypes[0] == "StatusCode" || f.retTypes[1] == "StatusCode") {
-		for i, t := range f.retTypes {
-			if _, ok := specialReturnTypes[t]; !ok && len(s.fields(t, "")) == 1 && !s.IsType(t, TYPE_PTR) {
-				f.primaryIdx = i
-				f.stdout = true
-			}
+	for i, t := range f.retTypes {
+		if t == "error" {
+			f.retTypes[i] = "StatusCode" // errors are returned as exit status
+		} else if t == "StatusCode" {
+			f.intStatus = true
 		}
 	}
+	s.setReturnConvention(&f)
+	if ReturnConvention == "var" && !strings.HasPrefix(name, "GOTOSH_ANON_") {
+		// Anonymous functions are written to stdout since runtime functions call them as $(f).
+		f.primaryIdx = -1
+		f.stdout = false
+	}
 	s.ScanToken('{')
+	open := s.Position.Offset
 	s.Writeln(f.expr + "() {")
 	s.cl = append(s.cl, "}")
 	for i, arg := range args {
@@ -799,9 +1238,25 @@ func (s *state) compileFunc(name, shname string, args []string, argTypes []Type)
 		}
 	}
 	s.funcs[name] = f
+	w, hasDefer := s.w, s.hasDefer
+	var body bytes.Buffer
+	s.w, s.hasDefer = &body, false
 	s.compile(len(s.cl) - 1)
+	s.w = w
+	if s.hasDefer {
+		indent := strings.Repeat("  ", len(s.cl)+1)
+		fmt.Fprintln(w, indent+"local GOTOSH_DEFER= GOTOSH_DEFER_CALL=")
+		fmt.Fprintln(w, indent+`trap 'trap - RETURN; eval "$GOTOSH_DEFER"' RETURN`)
+	}
+	body.WriteTo(w)
+	s.hasDefer = hasDefer
 	s.vars = previousVars
+	s.locals = previousLocals
 	s.funcName = previousFuncName
+	if Optimize && previousFuncName == "" {
+		f.inline = s.inlineBody(name, &f, args, open, s.Position.Offset)
+		s.funcs[name] = f
+	}
 	return f
 }
 
@@ -823,6 +1278,7 @@ func (s *state) procFunc() {
 		shname = s.packageName + "." + shname
 	}
 	f := s.compileFunc(name, strings.ReplaceAll(shname, ".", "__"), args, argTypes)
+	s.declare(f.expr)
 	s.funcs[s.packageName+"."+name] = f
 	if n, found := strings.CutPrefix(name, "GOTOSH_FUNC_"); found {
 		s.funcs[strings.ReplaceAll(n, "_", ".")] = f
@@ -846,6 +1302,13 @@ func (s *state) procFor() {
 
 	continueExpr := &shExpression{}
 	if expr := strings.TrimPrefix(e.expr, "#RANGE#"); expr != e.expr {
+		if pre := s.takePre(); pre != "" {
+			s.WriteString(pre)
+		}
+		if e.primaryIdx < 0 {
+			s.Writeln(expr) // the loop reads the elements from the returned array
+			expr = `"${` + e.RetVarName(0) + `[@]}"`
+		}
 		var k, v = "_", "_"
 		if len(e.lhs) > 0 && e.lhs[0] != "_" {
 			k = e.lhs[0]
@@ -868,11 +1331,13 @@ func (s *state) procFor() {
 	} else {
 		cond := "true"
 		if e.AsValue() != "" {
-			cond = "[ " + e.AsValue() + " -ne 0 ]"
+			cond = s.hoist(e).AsCondition()
 		}
-		s.Writeln("while " + cond + "; do :")
+		// hoisted calls are in the condition to be evaluated every iteration
+		s.Writeln("while " + s.takePre() + cond + "; do :")
 		if s.lastToken == ';' {
 			continueExpr = s.readExpression("", "{", false)
+			continueExpr.pre = s.takePre()
 		}
 	}
 	s.loopInfo = append(s.loopInfo, loopInfo{len(s.cl), continueExpr})
@@ -885,14 +1350,16 @@ func (s *state) procIf() {
 		s.writeExpr(e, "")
 		e = s.readExpression("bool", "{", false)
 	}
-	s.Writeln("if [ " + e.AsValue() + " -ne 0 ]; then :")
+	cond := s.hoist(e).AsCondition()
+	s.Writeln("if " + s.takePre() + cond + "; then :")
 	s.cl = append(s.cl, "fi")
 }
 
 func (s *state) procElse() {
 	s.bufLine = "" // cancel fi
 	if s.Scan() == scanner.Ident && s.TokenText() == "if" {
-		s.Writeln("elif [ " + s.readExpression("bool", "{", false).AsValue() + " -ne 0 ]; then :")
+		cond := s.hoist(s.readExpression("bool", "{", false)).AsCondition()
+		s.Writeln("elif " + s.takePre() + cond + "; then :")
 	} else {
 		s.Writeln("else")
 	}
@@ -901,6 +1368,9 @@ func (s *state) procElse() {
 
 func (s *state) compile(endDepth int) {
 	for tok := s.ScanWC(); tok != scanner.EOF; tok = s.ScanWC() {
+		if len(s.cl) == 0 && s.chunks != nil {
+			s.beginChunk(tok == scanner.Comment)
+		}
 		if tok == '}' && len(s.cl) > 0 {
 			s.EndBlock()
 			if len(s.cl) == endDepth {
@@ -914,6 +1384,9 @@ func (s *state) compile(endDepth int) {
 			}
 		} else if tok == scanner.Ident {
 			t := s.TokenText()
+			if t != "package" && t != "import" && t != "type" && t != "else" {
+				s.annotate(t != "func" || len(s.cl) > 0)
+			}
 			switch {
 			case t == "package" && len(s.cl) == 0:
 				s.packageName = s.ScanIdent()
@@ -944,14 +1417,25 @@ func (s *state) compile(endDepth int) {
 			case t == "return":
 				s.procReturn()
 			case t == "go":
-				s.Writeln(s.readExpression("", "", false).AsExec() + " &")
+				e := s.readExpression("", "", false).AsExec()
+				s.Writeln(s.takePre() + e + " &")
 			case t == "defer":
-				s.Writeln("# defer " + s.readExpression("", "", false).AsExec())
+				// deferred calls are run by the RETURN trap set at the top of the function.
+				// The arguments are evaluated here as Go does, and saved quoted by printf %q.
+				s.hasDefer = true
+				e := s.readExpression("", "", false)
+				if pre := s.takePre(); e.typ == "" && simpleCommand(e.expr) {
+					exec := strings.TrimPrefix(e.AsExec(), e.expr)
+					s.Writeln(pre + `printf -v GOTOSH_DEFER_CALL '%q ' ` + e.expr + `; GOTOSH_DEFER="$GOTOSH_DEFER_CALL` + exec + `; $GOTOSH_DEFER"`)
+				} else {
+					s.Writeln(pre + `GOTOSH_DEFER=` + quoteShellString(e.AsExec()) + `"; $GOTOSH_DEFER"`)
+				}
 			default:
 				s.skipNextScan = true
 				s.writeExpr(s.readExpression("", "", true), "")
 			}
 		} else if tok == '*' || tok == '&' {
+			s.annotate(true)
 			s.skipNextScan = true
 			s.writeExpr(s.readExpression("", "", true), "")
 		} else {
@@ -965,31 +1449,98 @@ func (s *state) Compile(r io.Reader, srcName string) error {
 	if err := s.loadRuntimeDefinitions(); err != nil {
 		return err
 	}
-	s.Init(r)
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.src = string(src)
+	s.panics = s.panics || Checked || callsPanic(s.src)
+	s.infNaN = s.infNaN || usesInfNaN(s.src)
+	s.Init(strings.NewReader(s.src))
 	s.Filename = srcName
 	s.imports = map[string]string{}
 	s.compile(-1)
-	return nil
+	return errors.Join(s.errs...)
 }
 
-func CompileFiles(sources []string) error {
-	s := newState()
-	s.Writeln("#!/bin/bash")
-	s.Writeln("")
-
-	for _, srcPath := range sources {
-		r, err := os.Open(srcPath)
+func (s *state) compileFiles(sources []string) error {
+	srcs := make([][]byte, len(sources))
+	for i, srcPath := range sources {
+		src, err := os.ReadFile(srcPath)
 		if err != nil {
 			return err
 		}
-		defer r.Close()
-		if err := s.Compile(r, srcPath); err != nil {
+		srcs[i] = src
+		// a function may call the function panicking in the later file
+		s.panics = s.panics || callsPanic(string(src))
+		s.infNaN = s.infNaN || usesInfNaN(string(src))
+	}
+	for i, srcPath := range sources {
+		if err := s.Compile(bytes.NewReader(srcs[i]), srcPath); err != nil {
 			return err
 		}
 	}
+	return nil
+}
+
+func CompileFiles(sources []string) error {
+	if err := checkFloatBackend(); err != nil {
+		return err
+	}
+	if ReturnConvention != "stdout" && ReturnConvention != "var" {
+		return fmt.Errorf("unsupported return convention: %s", ReturnConvention)
+	}
+	s := newState()
+	w := s.w
+	var body bytes.Buffer
+	s.w = &body
+	if Optimize {
+		s.chunks = []*chunk{}
+	}
+	if err := s.compileFiles(sources); err != nil {
+		return err
+	}
+	if _, ok := s.funcs["main.main"]; ok && s.chunks != nil {
+		s.chunks = s.liveChunks()
+	}
+	for _, c := range s.chunks {
+		c.text.WriteTo(&body)
+	}
+	// the ERR trap reports failed commands (--debug) and returns from the functions while panicking
+	var traps []string
+	if Debug {
+		s.useRuntime("debug.init")
+		traps = append(traps, s.useRuntime("debug.err")+` $? "$LINENO" "$BASH_COMMAND"`)
+	}
+	panics := s.funcs["panic.raise"].funcUsed
+	if panics {
+		traps = append(traps, "if "+s.useRuntime("panic.unwind")+"; then return 2; fi")
+	}
+	s.w = w
+	s.Writeln("#!/bin/bash")
 	if f, ok := s.funcs["main.main"]; ok {
+		// runtime functions are defined first to be called from global initializers.
 		s.emitUsedRuntime()
+		if s.funcs["float.bc"].funcUsed {
+			s.Writeln(s.funcs["float.coproc"].expr)
+		}
+		if Debug {
+			s.Writeln(s.funcs["debug.init"].expr)
+		}
+		if len(traps) > 0 {
+			s.Writeln("set -E")
+			s.Writeln("trap " + quoteShellString(strings.Join(traps, "; ")) + " ERR")
+		}
+		if panics {
+			// a panic in $(f) whose status is not checked is printed at the end
+			s.Writeln("trap " + s.funcs["panic.unwind"].expr + " EXIT")
+		}
+		s.Writeln("")
+		body.WriteTo(s.w)
 		s.Writeln(f.expr + " \"${@}\"")
+	} else {
+		s.Writeln("")
+		body.WriteTo(s.w)
 	}
 	return nil
 }
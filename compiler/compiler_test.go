@@ -2,6 +2,7 @@ package compiler
 
 import (
 	"bytes"
+	"io"
 	"slices"
 	"strings"
 	"testing"
@@ -94,6 +95,11 @@ func main() {
 	println(strings.TrimSpace(" abc "))
 	println(strings.TrimPrefix("abc", "a"))
 	println(strings.TrimSuffix("abc", "c"))
+	println(strings.HasPrefix("abc", "a"), strings.HasSuffix("abc", "c"), strings.LastIndex("abca", "a"))
+	println(strings.Count("abc", "b"), strings.Repeat("a", 3), strings.EqualFold("a", "A"), strings.Title("a b"))
+	println(strings.Trim(" a ", " "), strings.TrimLeft(" a", " "), strings.TrimRight("a ", " "))
+	println(strings.Replace("aaa", "a", "b", 2), strings.Map(func(r rune) rune { return r }, "a"))
+	println(strings.Fields(" a b "), strings.SplitN("a,b", ",", 2))
 }`
 	s := newState()
 	var out bytes.Buffer
@@ -103,11 +109,464 @@ func main() {
 	}
 	s.emitUsedRuntime()
 	got := out.String()
-	for _, name := range []string{"ReplaceAll", "ToUpper", "ToLower", "Contains", "Index", "TrimSpace", "TrimPrefix", "TrimSuffix"} {
+	for _, name := range []string{"ReplaceAll", "ToUpper", "ToLower", "Contains", "Index", "TrimSpace", "TrimPrefix", "TrimSuffix",
+		"HasPrefix", "HasSuffix", "LastIndex", "Count", "Repeat", "EqualFold", "Title", "Trim", "TrimLeft", "TrimRight",
+		"Replace", "Map", "Fields", "SplitN"} {
 		if !strings.Contains(got, "GOTOSH_RT_strings__"+name+"() {") {
 			t.Errorf("runtime function %s was not emitted:\n%s", name, got)
 		}
 	}
+	// the values are returned in GOTOSH_RET_0 to keep trailing newlines without forking
+	if strings.Contains(got, "$(GOTOSH_RT_strings__") {
+		t.Errorf("strings function is called in a subshell:\n%s", got)
+	}
+}
+
+// compileSnippet compiles src and returns the script with the used runtime functions.
+func compileSnippet(t *testing.T, file, src string) string {
+	t.Helper()
+	s := newState()
+	var out bytes.Buffer
+	s.w = &out
+	if err := s.Compile(strings.NewReader(src), file); err != nil {
+		t.Fatal(err)
+	}
+	s.emitUsedRuntime()
+	return out.String()
+}
+
+func TestCompileSnippets(t *testing.T) {
+	defer func(d, tr bool) { Debug, Trace = d, tr }(Debug, Trace)
+	for _, tc := range []struct {
+		file         string
+		debug, trace bool
+		src          string
+		want         []string
+		notWant      []string
+	}{
+		{file: "runtime_strconv_test.go", src: `package main
+import "strconv"
+func main() {
+	n, err := strconv.Atoi("12")
+	v, err := strconv.ParseInt("ff", 16, 64)
+	f, err := strconv.ParseFloat("1e3", 64)
+	b, err := strconv.ParseBool("true")
+	u, err := strconv.Unquote(strconv.Quote("a"))
+	println(n, v, f, b, u, err, strconv.FormatInt(v, 2), strconv.FormatFloat(f, 'e', -1, 64))
+}`,
+			want: []string{
+				`n=$(GOTOSH_RT_strconv__Atoi "12")` + "\n" + `  local err="$?"`,
+				`v=$(GOTOSH_RT_strconv__ParseInt "ff" 16 64)`,
+				`GOTOSH_RT_strconv__Unquote "$(GOTOSH_RT_strconv__Quote "a")"` + "\n" + `  local err="$?"` + "\n" + `  local u="$GOTOSH_RET_0"`,
+				"GOTOSH_RT_strconv__ParseInt() {",
+				"GOTOSH_RT_utf8__encode() {",
+				"GOTOSH_RT_fmt__float() {",
+			}},
+		{file: "runtime_fileinfo_test.go", src: `package main
+import "os"
+func main() {
+	st, err := os.Stat("a.txt")
+	if os.IsNotExist(err) {
+		return
+	}
+	println(st.Name(), st.Size(), st.IsDir(), st.Mode().Perm(), st.ModTime().Unix())
+	entries, _ := os.ReadDir(".")
+	for _, e := range entries {
+		println(e.Name(), e.IsDir())
+	}
+}`,
+			want: []string{
+				`local st__mode="$GOTOSH_RET_0__mode"`,
+				`if [ $(( $err == 2 )) -ne 0 ]; then`,
+				`"${st__name}" ${st__size} $(( st__mode >> 31 & 1 )) $(( ${st__mode} & 8#777 )) $(( ${st__modTime} / 1000000000 ))`,
+				`local entries=("${GOTOSH_RET_0[@]}")`,
+				`"${e##*/}" $(( ${e%%/*} >> 31 & 1 ))`,
+				"GOTOSH_RT_os__Lstat() {",
+			}},
+		{file: "runtime_filepath_test.go", src: `package main
+import (
+	"io/fs"
+	"path/filepath"
+)
+func main() {
+	println(filepath.Join("a", "b"), filepath.Base("a/b"))
+	filepath.WalkDir(".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.Name() == "skip" {
+			return filepath.SkipDir
+		}
+		return err
+	})
+}`,
+			want: []string{
+				`"$(GOTOSH_RT_filepath__Join "a" "b")" "$(GOTOSH_RT_filepath__Base "a/b")"`,
+				`GOTOSH_RT_filepath__WalkDir "." GOTOSH_ANON_0`,
+				"return 254",
+				"return $err",
+				"GOTOSH_RT_filepath__normalize() {",
+				"GOTOSH_RT_filepath__walktree() {",
+				"GOTOSH_RT_fs__modetype() {",
+			}},
+		{file: "bufio_scanner_test.go", src: `package main
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+func main() {
+	sc := bufio.NewScanner(os.Stdin)
+	sc.Split(bufio.ScanWords)
+	for sc.Scan() {
+		fmt.Println(sc.Text())
+	}
+	if !sc.Scan() {
+		fmt.Println(sc.Err() == nil)
+	}
+	r := bufio.NewReader(os.Stdin)
+	line, err := r.ReadString('\n')
+	fmt.Println(line, err)
+}`,
+			want: []string{
+				`local sc__fd="$GOTOSH_RET_0__fd"`,
+				"sc__split=1",
+				"while GOTOSH_RT_bufio__scan sc; do :",
+				`printf '%s\n' "${sc__text}"`,
+				"if ! GOTOSH_RT_bufio__scan sc; then :",
+				`GOTOSH_RT_bufio__Reader__ReadString "$r" '\n'`,
+				"GOTOSH_RT_bufio__scan() {",
+			}},
+		{file: "io_functions_test.go", src: `package main
+import (
+	"fmt"
+	"io"
+	"os"
+)
+func main() {
+	f, _ := os.Open("a.txt")
+	n, err := io.Copy(os.Stdout, f)
+	data, _ := io.ReadAll(os.Stdin)
+	fmt.Fprintln(io.Discard, n, err, string(data))
+	w := io.MultiWriter(os.Stdout, os.Stderr)
+	io.WriteString(w, "x")
+	fmt.Fprintln(w, "y")
+}`,
+			want: []string{
+				"GOTOSH_RT_io__Copy 1 $f",
+				`local n="$GOTOSH_RET_0"`,
+				"GOTOSH_RT_io__ReadAll 0",
+				">&/dev/null",
+				`local w="1 2"`,
+				`GOTOSH_RT_io__WriteString "$w" "x"`,
+				`GOTOSH_RT_io__write "$w" printf`,
+			}},
+		{file: "exec_command_test.go", src: `package main
+import (
+	"fmt"
+	"os/exec"
+)
+func main() {
+	cmd := exec.Command("printf", "[%s]\n", "a b")
+	cmd.Dir = "/tmp"
+	out, err := cmd.Output()
+	if ee, ok := err.(*exec.ExitError); ok {
+		fmt.Println(ee.ExitCode())
+	}
+	fmt.Print(out)
+}`,
+			want: []string{
+				`GOTOSH_RET_0__Args=("printf" $'[%s]\n' "a b")`,
+				`local cmd__Args=("${GOTOSH_RET_0__Args[@]}")`,
+				`cmd__Dir="/tmp"`,
+				"GOTOSH_RT_exec__output cmd output",
+				"GOTOSH_RET_1=$(( $err != 0 ))",
+				"GOTOSH_RT_exec__run()",
+			}},
+		{file: "exec_pipe_cmd_test.go", src: `package main
+import (
+	"os"
+	"os/exec"
+	"github.com/binzume/gotosh/shell"
+)
+func upper(in, out *os.File, _ ...string) shell.StatusCode { return 0 }
+func main() {
+	cmd := exec.Command("tr", "a-z", "A-Z")
+	shell.ExecPipe(shell.Cmd("grep", "-v", "x"), shell.Bind(upper), shell.BindCmd(cmd))
+}`,
+			want: []string{
+				`"grep" "-v" "x" | upper 0 1 | GOTOSH_RT_exec__run cmd pipe`,
+				"GOTOSH_RT_exec__run() {",
+			}},
+		{file: "exec_pipe_all_test.go", src: `package main
+import (
+	"fmt"
+	"github.com/binzume/gotosh/shell"
+)
+func main() {
+	statuses := shell.ExecPipeAll(shell.Cmd("false"), shell.Cmd("cat"))
+	fmt.Println(statuses)
+}`,
+			want: []string{
+				`"false" | "cat"; GOTOSH_RET_0=("${PIPESTATUS[@]}")`,
+				`local statuses=("${GOTOSH_RET_0[@]}")`,
+			}},
+		{file: "time_functions_test.go", src: `package main
+import (
+	"fmt"
+	"time"
+)
+func main() {
+	start := time.Now()
+	time.Sleep(2 * time.Second)
+	d := time.Since(start)
+	fmt.Println(d, start.Format(time.RFC3339))
+	p, err := time.Parse(time.DateOnly, "2024-01-02")
+	fmt.Println(time.Duration(p.Unix()) * time.Second, err)
+	u := start.UTC().Add(time.Hour)
+	fmt.Println(u.Format(time.Kitchen))
+}`,
+			want: []string{
+				`local start="$(GOTOSH_RT_time__Now)"`,
+				"GOTOSH_RT_time__Sleep $(( 2*1000000000 ))",
+				`"$(GOTOSH_RT_time__Duration__String $d)"`,
+				`"$(GOTOSH_RT_time__Time__Format "$start" "2006-01-02T15:04:05Z07:00")"`,
+				`GOTOSH_RT_time__Parse "2006-01-02" "2024-01-02"`,
+				`"$(GOTOSH_RT_time__Duration__String $(( $(( "$p" / 1000000000 ))*1000000000 )))"`,
+				`local u="$(GOTOSH_RT_time__Time__Add "($(( "$start" ))+0*0)" 3600000000000)"`,
+				"GOTOSH_RT_time__date() {",
+			}},
+		{file: "defer_test.go", src: `package main
+import "fmt"
+func main() {
+	for i := 0; i < 2; i++ {
+		defer fmt.Println("loop", i)
+	}
+	x := 1
+	defer func() { fmt.Println(x) }()
+}`,
+			want: []string{
+				`printf -v GOTOSH_DEFER_CALL '%q ' printf '%s %s\n' "loop" "$i"; GOTOSH_DEFER="$GOTOSH_DEFER_CALL; $GOTOSH_DEFER"`,
+				`printf -v GOTOSH_DEFER_CALL '%q ' GOTOSH_ANON_0; GOTOSH_DEFER="$GOTOSH_DEFER_CALL; $GOTOSH_DEFER"`,
+			}},
+		{file: "signal_notify_test.go", src: `package main
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+func main() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer fmt.Println("cleanup")
+	sig := <-sigs
+	fmt.Println(sig)
+	signal.Ignore(syscall.SIGHUP)
+	signal.Reset()
+}`,
+			want: []string{
+				"main() {\n  local GOTOSH_DEFER= GOTOSH_DEFER_CALL=\n  trap 'trap - RETURN; eval \"$GOTOSH_DEFER\"' RETURN\n",
+				`mkfifo $_tmp/f`,
+				`trap "printf '%s\n' 2 >&$sigs" 2; trap "printf '%s\n' 15 >&$sigs" 15`,
+				`printf -v GOTOSH_DEFER_CALL '%q ' printf '%s\n' "cleanup"; GOTOSH_DEFER="$GOTOSH_DEFER_CALL; $GOTOSH_DEFER"`,
+				"GOTOSH_RT_chan__recv $sigs\n  local sig=\"$GOTOSH_RET_0\"",
+				`"$(GOTOSH_RT_syscall__Signal__String $sig)"`,
+				"trap '' 1",
+				"trap - 1 2 3 15",
+			}},
+		{file: "strings_split_test.go", src: `package main
+import "strings"
+func main() {
+	parts := strings.Split("a,*", ",")
+	for _, p := range strings.Split("a::b", "::") {
+		println(p)
+	}
+	println(len(strings.Split("a,b", ",")), strings.Join(parts, ", "))
+	println(strings.Join(strings.Split("x y,*", ","), "+"))
+}`,
+			want: []string{
+				`GOTOSH_RT_strings__Split "a,*" ","` + "\n" + `  local parts=("${GOTOSH_RET_0[@]}")`,
+				`GOTOSH_RT_strings__Split "a::b" "::"` + "\n",
+				`for p in "${GOTOSH_RET_0[@]}"; do`,
+				`GOTOSH_RT_strings__Split "a,b" ","; local GOTOSH_TMP_0=("${GOTOSH_RET_0[@]}")`,
+				`GOTOSH_RT_strings__Join "${parts[@]}" ", "`,
+				`GOTOSH_RT_strings__Split "x y,*" ","; local GOTOSH_TMP_2=("${GOTOSH_RET_0[@]}"); GOTOSH_RT_strings__Join "${GOTOSH_TMP_2[@]}" "+"`,
+				"GOTOSH_RT_strings__Join() {",
+			}},
+		{file: "strings_cut_test.go", src: `package main
+import "strings"
+func main() {
+	before, after, found := strings.Cut("k=v", "=")
+	var sb strings.Builder
+	sb.WriteString(before)
+	sb.WriteString(after)
+	println(sb.String(), sb.Len(), found)
+	sb.Reset()
+}`,
+			want: []string{
+				`GOTOSH_RT_strings__Cut "k=v" "="`,
+				`local before="$GOTOSH_RET_0"`,
+				`local after="$GOTOSH_RET_1"`,
+				`local found="$GOTOSH_RET_2"`,
+				`sb="$sb""$before"`,
+				`"$sb" ${#sb}`,
+				"  sb=\n",
+			}},
+		{file: "fmt_test.go", src: `package main
+import "fmt"
+type Point struct { X, Y int }
+func main() {
+  name := "abc"
+  p := Point{1, 2}
+  ok := true
+  fmt.Println(name, 123)
+  fmt.Printf("%s=%04d\n", name, 1)
+  fmt.Println(p, ok, []int{1, 2})
+  fmt.Printf("%+v %q\n", p, name)
+  s := fmt.Sprint(name, 1)
+}`,
+			want: []string{
+				`printf '%s %s\n' "$name" 123`,
+				`printf '%s=%04d\n' "$name" 1`,
+				`GOTOSH_RT_fmt__printf $'%v %v %v\n' t 'main.Point' 2 X i 'int' "$p__X" Y i 'int' "$p__Y" b 'bool' "$ok" ai '[]int' 2 1 2`,
+				`GOTOSH_RT_fmt__printf $'%+v %q\n' t 'main.Point'`,
+				`local s="$name"1`,
+				"GOTOSH_RT_fmt__sprintf() {",
+				"GOTOSH_RT_fmt__value() {",
+				"GOTOSH_RT_fmt__float() {",
+			}},
+		{file: "scan_test.go", src: `package main
+import "fmt"
+func main() {
+  var host string
+  var port int
+  n, err := fmt.Sscanf("localhost 80", "%s %d", &host, &port)
+  fmt.Scan(&port)
+}`,
+			want: []string{
+				`GOTOSH_RT_fmt__sscanf "localhost 80" "%s %d" s "host" i "port"`,
+				`local err="$?"`,
+				`local n="$GOTOSH_RET_0"`,
+				`GOTOSH_RT_fmt__scan i "port"`,
+				"GOTOSH_RT_fmt__scanvalue() {",
+			}},
+		{file: "flag_parse_test.go", src: `package main
+import (
+	"flag"
+	"fmt"
+)
+func main() {
+	name := flag.String("name", "gopher", "` + "`user`" + ` name")
+	var n int
+	flag.IntVar(&n, "n", 1, "count")
+	count := flag.Int("c", 0, "")
+	flag.Parse()
+	for i := 0; i < *count; i++ {
+	}
+	fmt.Println(*name, n, flag.Args())
+}`,
+			want: []string{
+				"GOTOSH_FLAG_n=0 GOTOSH_FLAG_nargs=0 GOTOSH_FLAG_out=2 flag__Usage=GOTOSH_RT_flag__usage\n",
+				"GOTOSH_RT_flag__define s \"name\" \"gopher\" \"\\`user\\` name\" \"\"\n  typeset -n name=\"$GOTOSH_RET_0\"",
+				`GOTOSH_RT_flag__define i "n" 1 "count" "n"`,
+				`GOTOSH_RT_flag__parse "$@"`,
+				`[ $(( i<count )) -ne 0 ]`,
+				`as '[]string' "${#GOTOSH_FLAG_args[@]}" "${GOTOSH_FLAG_args[@]}"`,
+				"GOTOSH_RT_flag__PrintDefaults() {",
+				"GOTOSH_RT_time__ParseDuration() {",
+			}},
+		{file: "sort_test.go", src: `package main
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+)
+func main() {
+	a := []string{"b", "a"}
+	sort.Strings(a)
+	n := []int{2, 1}
+	slices.Sort(n)
+	sort.Slice(a, func(i, j int) bool { return len(a[i]) < len(a[j]) })
+	m := map[string]int{"x": 1}
+	keys := slices.Sorted(maps.Keys(m))
+	fmt.Println(slices.Contains(n, 1), slices.Max(n), keys)
+}`,
+			want: []string{
+				`GOTOSH_RT_sort__sorted s "${a[@]}"; a=("${GOTOSH_RET_0[@]}")`,
+				`GOTOSH_RT_sort__sorted i "${n[@]}"; n=("${GOTOSH_RET_0[@]}")`,
+				`echo $(( ${#a[$i]}<${#a[$j]} ))`,
+				`GOTOSH_RT_sort__slice a GOTOSH_ANON_0`,
+				"GOTOSH_RT_sort__sorted s \"${!m[@]}\"\n  local keys=(\"${GOTOSH_RET_0[@]}\")",
+				`$(( $(GOTOSH_RT_slices__index 1 "${n[@]}") >= 0 ))`,
+				`GOTOSH_RT_slices__extreme max i "${n[@]}"; local GOTOSH_TMP_0="$GOTOSH_RET_0"`,
+				`i 'int' "$GOTOSH_TMP_0"`,
+				"LC_ALL=C sort -z",
+			}},
+		{file: "debug.go", debug: true, trace: true, src: `package main
+import "fmt"
+func main() {
+	if x := 1; x > 0 {
+		fmt.Println("it's", x)
+	} else {
+		fmt.Println("no")
+	}
+}
+`,
+			want: []string{
+				"# debug.go:3\nmain() {",
+				"  # debug.go:4\n  printf '+ %s\\n' 'debug.go:4: if x := 1; x > 0 {' >&2\n",
+				`printf '+ %s\n' $'debug.go:5: fmt.Println(\"it\'s\", x)' >&2`,
+				"  else\n    # debug.go:7\n",
+			},
+			// function declarations are not traced
+			notWant: []string{"+ %s\\n' 'debug.go:3"},
+		},
+		{file: "debug.go", debug: true, src: `package main
+import (
+	"os"
+	"strconv"
+)
+func main() {
+	n, err := strconv.Atoi("x")
+	os.Mkdir("a", 0755)
+	f, _ := os.Open("b")
+	println(n, err, f)
+}
+`,
+			want: []string{
+				`n=$(GOTOSH_RT_strconv__Atoi "x") && err=0 || err=$?` + "\n",
+				`mkdir "a" || :` + "\n",
+				`else (exit 2); fi || :` + "\n",
+			}},
+	} {
+		Debug, Trace = tc.debug, tc.trace
+		got := compileSnippet(t, tc.file, tc.src)
+		for _, want := range tc.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: output does not contain %q:\n%s", tc.file, want, got)
+			}
+		}
+		for _, notWant := range tc.notWant {
+			if strings.Contains(got, notWant) {
+				t.Errorf("%s: output contains %q:\n%s", tc.file, notWant, got)
+			}
+		}
+	}
+}
+
+func TestSimpleCommand(t *testing.T) {
+	for cmd, want := range map[string]bool{
+		`printf '%s;%s\n' "a|b" "$(f "x)")" $'it\'s;' ${a[@]}`: true,
+		`eval "exec "$f"<&- "$f">&-"`:                          true,
+		`f "$(( (i+1) * 2 ))"`:                                 true,
+		`exec {fd}>&-`:                                         false,
+		`a; b`:                                                 false,
+		`f | g`:                                                false,
+		`f "unterminated`:                                      false,
+	} {
+		if simpleCommand(cmd) != want {
+			t.Errorf("simpleCommand(%q) = %v", cmd, !want)
+		}
+	}
 }
 
 func TestRuntimeDependenciesAreBestEffort(t *testing.T) {
@@ -186,7 +645,7 @@ func Test_readExpression(t *testing.T) {
 		{"1 + 1", shExpression{expr: "1+1", typ: "INT_EXPR", retTypes: []Type{"int"}}, scanner.EOF},
 		{"1 == 1", shExpression{expr: "1 == 1", typ: "INT_EXPR", retTypes: []Type{"bool"}}, scanner.EOF},
 		{"!true", shExpression{expr: "!1", typ: "INT_EXPR", retTypes: []Type{"bool"}}, scanner.EOF},
-		{"1.5 * 1.5", shExpression{expr: "1.5*1.5", typ: "FLOAT_EXPR", retTypes: []Type{"float32"}}, scanner.EOF},
+		{"1.5 * 1.5", shExpression{expr: "1.5*1.5", typ: "FLOAT_EXPR", retTypes: []Type{"float64"}}, scanner.EOF},
 		{`"ABC" == "DEF"`, shExpression{expr: `"ABC" == "DEF"`, typ: "STR_CMP", retTypes: []Type{"bool"}}, scanner.EOF},
 		{`len([]int{1,2,3})`, shExpression{expr: `3`, retTypes: []Type{"int"}}, scanner.EOF},
 		{`len(map[int]int{1:2, 2:3})`, shExpression{expr: `2`, retTypes: []Type{"int"}}, scanner.EOF},
@@ -222,3 +681,363 @@ func Test_readExpression(t *testing.T) {
 		}
 	}
 }
+
+func TestCompletion(t *testing.T) {
+	const src = `package main
+import "flag"
+func main() {
+	flag.String("config", "", "` + "`path`" + ` to the config")
+	flag.Int("n", 1, "count")
+	var v bool
+	flag.BoolVar(&v, "v", false, "verbose")
+	flag.Parse()
+}`
+	s := newState()
+	s.w = io.Discard
+	if err := s.Compile(strings.NewReader(src), "completion_test.go"); err != nil {
+		t.Fatal(err)
+	}
+	for shell, wants := range map[string][]string{
+		"bash": {
+			`-config | --config) COMPREPLY=($(compgen -f -- "$cur")); return ;;`,
+			`-n | --n) COMPREPLY=(); return ;;`,
+			`-*) COMPREPLY=($(compgen -W '-config -n -v' -- "$cur")) ;;`,
+			"complete -o filenames -F _gotosh_my_tool my-tool\n",
+		},
+		"zsh": {
+			`'(-config --config)'{-config,--config}'[path to the config]:path:_files' \`,
+			`'(-n --n)'{-n,--n}'[count]:int:' \`,
+			`'(-v --v)'{-v,--v}'[verbose]' \`,
+			"compdef _gotosh_my_tool my-tool\n",
+		},
+	} {
+		var out bytes.Buffer
+		if err := writeCompletion(&out, shell, "my-tool", s.flags); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("%s completion does not contain %q:\n%s", shell, want, out.String())
+			}
+		}
+	}
+	if err := writeCompletion(io.Discard, "fish", "my-tool", s.flags); err == nil {
+		t.Error("unsupported shell should be an error")
+	}
+}
+
+func TestTranslateRegexp(t *testing.T) {
+	for _, tc := range []struct{ pattern, want, err string }{
+		{pattern: `a+b`, want: ":0:a+b"},
+		{pattern: `(?:ab)+(c)`, want: ":0,2:(ab)+(c)"},
+		{pattern: `(?P<user>\w+)@(\w+)\.com`, want: ":0,1=user,2:([0-9A-Z_a-z]+)@([0-9A-Z_a-z]+)\\.com"},
+		{pattern: `^\d{2,4}$`, want: "^$:0:^[0-9]{2,4}$"},
+		{pattern: `(?i)go.`, want: ":0:[gG][oO][^\n]"},
+		{pattern: `[\]\-^\[a]`, want: ":0:[]a[^-]"},
+		{pattern: `[-^]`, want: ":0:[-^]"},
+		{pattern: `[^a-z]`, want: ":0:[^a-z]"},
+		{pattern: `a*?`, err: "non-greedy"},
+		{pattern: `\bgo`, err: `\b`},
+		{pattern: `(?m)^a`, err: "multi-line"},
+		{pattern: `a$|b`, err: "^ and $"},
+		{pattern: `\pL`, err: "Unicode"},
+		{pattern: `a(`, err: "missing closing )"},
+	} {
+		got, err := translateRegexp(tc.pattern)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("translateRegexp(%q) error = %v, want %q", tc.pattern, err, tc.err)
+			}
+		} else if err != nil || got != tc.want {
+			t.Errorf("translateRegexp(%q) = %q, %v, want %q", tc.pattern, got, err, tc.want)
+		}
+	}
+
+	s := newState()
+	s.w = io.Discard
+	err := s.Compile(strings.NewReader(`package main
+import "regexp"
+func main() {
+	re := regexp.MustCompile("a+?")
+}`), "regexp_test.go")
+	if err == nil || !strings.Contains(err.Error(), `regexp_test.go:4:32: regexp "a+?": non-greedy`) {
+		t.Errorf("Compile() error = %v", err)
+	}
+}
+
+func TestBcScript(t *testing.T) {
+	if got := bcScript("sqrt(2)"); got != "sqrt(2)" {
+		t.Errorf("bcScript(sqrt) = %q", got)
+	}
+	got := bcScript("go_round(2.5) + go_abs(-1)")
+	var defined []string
+	for _, line := range strings.Split(got, "\n") {
+		if name, ok := strings.CutPrefix(line, "define "); ok {
+			defined = append(defined, name[:strings.IndexByte(name, '(')])
+		}
+	}
+	if want := []string{"go_trunc", "go_floor", "go_round", "go_abs"}; !slices.Equal(defined, want) {
+		t.Errorf("bcScript(go_round) defines %v, want %v", defined, want)
+	}
+	if !strings.HasSuffix(got, "\ngo_round(2.5) + go_abs(-1)") {
+		t.Errorf("bcScript(go_round) = %q", got)
+	}
+}
+
+func TestFloatBackend(t *testing.T) {
+	defer func(b string) { FloatBackend = b }(FloatBackend)
+	src := `package main
+import "math"
+import "github.com/binzume/gotosh/shell"
+func main() {
+	shell.SetFloatPrecision(30)
+	x := math.Floor(2.5)
+	println(x)
+}
+`
+	for _, tc := range []struct{ backend, want, notWant string }{
+		{backend: "bc", want: `$(echo "scale=30;define go_trunc(x) {`, notWant: "awk"},
+		{backend: "awk", want: `$(GOTOSH_RT_float__awk "go_floor(2.5)")`, notWant: "bc -l"},
+		{backend: "auto", want: `$(BC_LINE_LENGTH=40 GOTOSH_RT_float__eval "scale=30;define go_trunc(x) {`, notWant: ""},
+		{backend: "coproc", want: `GOTOSH_RT_float__bc "scale=30;define go_trunc(x) {`, notWant: "$(GOTOSH_RT_float__bc"},
+	} {
+		FloatBackend = tc.backend
+		got := compileSnippet(t, "float.go", src)
+		if !strings.Contains(got, tc.want) || !strings.Contains(got, "GOTOSH_FLOAT_PREC=30") {
+			t.Errorf("%s: output doesn't contain %q:\n%s", tc.backend, tc.want, got)
+		}
+		if tc.notWant != "" && strings.Contains(got, tc.notWant) {
+			t.Errorf("%s: output contains %q:\n%s", tc.backend, tc.notWant, got)
+		}
+	}
+	FloatBackend = "dc"
+	if err := CompileFiles(nil); err == nil || !strings.Contains(err.Error(), "dc") {
+		t.Errorf("CompileFiles with unknown backend: %v", err)
+	}
+}
+
+func TestReturnConvention(t *testing.T) {
+	defer func(c string) { ReturnConvention = c }(ReturnConvention)
+	src := `package main
+import "fmt"
+func fib(n int) int {
+	if n < 2 {
+		return n
+	}
+	return fib(n-1) + fib(n-2)
+}
+func twice(n int) int { return fib(n) * 2 }
+func main() {
+	x := fib(10)
+	if fib(x) > 3 {
+		fmt.Println(twice(x), x)
+	}
+}
+`
+	for _, tc := range []struct {
+		convention string
+		want       []string
+	}{
+		{convention: "stdout", want: []string{
+			"echo $(( $(fib $(( n-1 )))+$(fib $(( n-2 ))) )); return",
+			"local x=$(fib 10)",
+		}},
+		{convention: "var", want: []string{
+			`GOTOSH_RET_0=$n; return`,
+			`fib $(( n-1 )); local GOTOSH_TMP_0="$GOTOSH_RET_0"; fib $(( n-2 )); local GOTOSH_TMP_1="$GOTOSH_RET_0"; GOTOSH_RET_0=$(( $GOTOSH_TMP_0+$GOTOSH_TMP_1 )); return`,
+			"fib 10\n  local x=\"$GOTOSH_RET_0\"",
+			`if fib $x; local GOTOSH_TMP_3="$GOTOSH_RET_0"; [ $(( $GOTOSH_TMP_3>3 )) -ne 0 ]; then :`,
+			`twice $x; local GOTOSH_TMP_4="$GOTOSH_RET_0"; printf '%s %s\n' "$GOTOSH_TMP_4" "$x"`,
+		}},
+	} {
+		ReturnConvention = tc.convention
+		got := compileSnippet(t, "fib.go", src)
+		for _, want := range tc.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: output doesn't contain %q:\n%s", tc.convention, want, got)
+			}
+		}
+	}
+	ReturnConvention = "stack"
+	if err := CompileFiles(nil); err == nil || !strings.Contains(err.Error(), "stack") {
+		t.Errorf("CompileFiles with unknown convention: %v", err)
+	}
+}
+
+func TestShortCircuit(t *testing.T) {
+	defer func(c string) { ReturnConvention = c }(ReturnConvention)
+	src := `package main
+import "strings"
+func ok(n int) bool { return n > 1 }
+func main() {
+	s := "abc"
+	if ok(2) || strings.HasPrefix(s, "a") && ok(1) {
+		println(s)
+	}
+}
+`
+	for _, tc := range []struct {
+		convention string
+		want       []string
+	}{
+		{convention: "stdout", want: []string{
+			`local GOTOSH_TMP_3=1; if ! (( "$(ok 2)" )); then GOTOSH_RT_strings__HasPrefix "$s" "a"; local GOTOSH_TMP_0="$GOTOSH_RET_0"; `,
+			`local GOTOSH_TMP_2=0; if (( $GOTOSH_TMP_0 )); then local GOTOSH_TMP_1="$(ok 1)"; (( $GOTOSH_TMP_1 )) && GOTOSH_TMP_2=1; fi; `,
+			`(( $GOTOSH_TMP_2 == 1 )) || GOTOSH_TMP_3=0; fi; [ $(( $GOTOSH_TMP_3 == 1 )) -ne 0 ]; then :`,
+		}},
+		{convention: "var", want: []string{
+			`if ok 2; local GOTOSH_TMP_0="$GOTOSH_RET_0"; local GOTOSH_TMP_4=1; if ! (( $GOTOSH_TMP_0 )); then `,
+			`if (( $GOTOSH_TMP_1 )); then ok 1; local GOTOSH_TMP_2="$GOTOSH_RET_0"; (( $GOTOSH_TMP_2 )) && GOTOSH_TMP_3=1; fi; `,
+		}},
+	} {
+		ReturnConvention = tc.convention
+		got := compileSnippet(t, "short_circuit.go", src)
+		for _, want := range tc.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: output doesn't contain %q:\n%s", tc.convention, want, got)
+			}
+		}
+	}
+}
+
+func TestOptimize(t *testing.T) {
+	defer func(o bool) { Optimize = o }(Optimize)
+	Optimize = true
+	src := `package main
+import "fmt"
+var factor = 3
+var unused = 42
+func scale(x int) int {
+	return x * factor
+}
+func dead() {
+	fmt.Println("dead")
+}
+func fact(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return n * fact(n-1)
+}
+func say(msg string) {
+	fmt.Println("say:", msg)
+}
+func main() {
+	fmt.Println(scale(1+1), fact(5))
+	say("hello")
+	factor := 1
+	fmt.Println(scale(factor))
+}
+`
+	var b bytes.Buffer
+	s := newState()
+	s.chunks = []*chunk{}
+	if err := s.Compile(strings.NewReader(src), "optimize.go"); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range s.liveChunks() {
+		c.text.WriteTo(&b)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"factor=$(( 3 ))",
+		"$(( (1+1)*factor ))",
+		`printf '%s %s\n' "say:" "hello"`,
+		"$(fact 5)",
+		"$(scale $factor)", // shadowed by the local variable
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, got)
+		}
+	}
+	for _, notWant := range []string{"unused=", "dead()", "say()"} {
+		if strings.Contains(got, notWant) {
+			t.Errorf("output contains %q:\n%s", notWant, got)
+		}
+	}
+}
+
+func TestChecked(t *testing.T) {
+	defer func(c bool) { Checked = c }(Checked)
+	Checked = true
+	src := `package main
+import "fmt"
+func f(a []int, s string, p *int, i, n int) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Println(r)
+		}
+	}()
+	fmt.Println(a[i], s[1:n], i/n, *p)
+	if i < len(a) && a[i] > 0 {
+		panic("positive")
+	}
+}
+`
+	got := compileSnippet(t, "checked.go", src)
+	for _, want := range []string{
+		`GOTOSH_RT_panic__recover; local r="$GOTOSH_RECOVERED"`,
+		`[[ "$r" != "" ]]`,
+		`GOTOSH_RT_panic__index "$i" "${#a[@]}" || return 2; `,
+		`GOTOSH_RT_panic__slice "1" "$n" "${#s}" "length" || return 2; `,
+		`GOTOSH_RT_panic__div "$(( n ))" || return 2; `,
+		`GOTOSH_RT_panic__nil "p" || return 2; `,
+		`GOTOSH_RT_panic__raise "positive"; return 2`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "GOTOSH_RT_panic__index \"") != 1 {
+		t.Errorf("index after && is checked:\n%s", got)
+	}
+}
+
+func TestPanicUnwind(t *testing.T) {
+	defer func(c string) { ReturnConvention = c }(ReturnConvention)
+	src := `package main
+import "fmt"
+func get(n int) int {
+	if n < 0 {
+		panic("negative")
+	}
+	return n
+}
+func parse(s string) (int, error) {
+	return get(len(s)), nil
+}
+func main() {
+	fmt.Println(get(1))
+	if get(2) > 1 {
+		get(3)
+	}
+	n, err := parse("x")
+	fmt.Println(n, err)
+}
+`
+	for _, tc := range []struct {
+		convention string
+		want       []string
+	}{
+		{convention: "stdout", want: []string{
+			`local GOTOSH_TMP_1; GOTOSH_TMP_1=$(get 1) || if GOTOSH_RT_panic__unwind; then return 2; fi; printf`,
+			`if local GOTOSH_TMP_2; GOTOSH_TMP_2=$(get 2) || if GOTOSH_RT_panic__unwind; then return 2; fi; [`,
+			`get 3 >/dev/null || if GOTOSH_RT_panic__unwind; then return 2; fi` + "\n",
+			"local err=\n  local n=\n  n=$(parse \"x\") && err=0 || { err=$?; if GOTOSH_RT_panic__unwind; then return 2; fi; }\n",
+		}},
+		{convention: "var", want: []string{
+			`get 1 || if GOTOSH_RT_panic__unwind; then return 2; fi; local GOTOSH_TMP_1="$GOTOSH_RET_0"; printf`,
+			`if get 2 || if GOTOSH_RT_panic__unwind; then return 2; fi; local GOTOSH_TMP_2="$GOTOSH_RET_0"; [`,
+			`get 3 || if GOTOSH_RT_panic__unwind; then return 2; fi` + "\n",
+			"local err=\n  parse \"x\" && err=0 || { err=$?; if GOTOSH_RT_panic__unwind; then return 2; fi; }\n",
+		}},
+	} {
+		ReturnConvention = tc.convention
+		got := compileSnippet(t, "unwind.go", src)
+		for _, want := range tc.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: output doesn't contain %q:\n%s", tc.convention, want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// flagDef is a flag defined by the flag package. kind is the same as the flag.define runtime: s, i, b or d.
+type flagDef struct {
+	name  string
+	kind  string
+	usage string
+}
+
+// argName returns the name of the flag argument and the usage without back quotes, like flag.UnquoteUsage.
+func (f *flagDef) argName() (string, string) {
+	if i := strings.IndexByte(f.usage, '`'); i >= 0 {
+		if j := strings.IndexByte(f.usage[i+1:], '`'); j >= 0 {
+			name := f.usage[i+1 : i+1+j]
+			return name, f.usage[:i] + name + f.usage[i+j+2:]
+		}
+	}
+	return map[string]string{"s": "string", "i": "int", "d": "duration"}[f.kind], f.usage
+}
+
+// compgen returns the completion type of the flag argument. Only string flags whose argument is named `file`, `path` or `dir` are completed.
+func (f *flagDef) compgen() string {
+	name, _ := f.argName()
+	if f.kind != "s" {
+		return ""
+	}
+	switch strings.ToLower(name) {
+	case "file", "filename", "path":
+		return "file"
+	case "dir", "directory":
+		return "dir"
+	}
+	return ""
+}
+
+// CompletionFiles writes a completion script of the flags defined in the sources to stdout.
+func CompletionFiles(shell, command string, sources []string) error {
+	s := newState()
+	s.w = io.Discard
+	if err := s.compileFiles(sources); err != nil {
+		return err
+	}
+	return writeCompletion(os.Stdout, shell, command, s.flags)
+}
+
+func writeCompletion(w io.Writer, shell, command string, flags []flagDef) error {
+	flags = append([]flagDef(nil), flags...)
+	sort.SliceStable(flags, func(i, j int) bool { return flags[i].name < flags[j].name })
+	fn := "_gotosh_" + regexp.MustCompile(`[^A-Za-z0-9_]`).ReplaceAllString(command, "_")
+	switch shell {
+	case "bash":
+		writeBashCompletion(w, fn, command, flags)
+	case "zsh":
+		writeZshCompletion(w, fn, command, flags)
+	default:
+		return fmt.Errorf("unsupported shell: %s", shell)
+	}
+	return nil
+}
+
+func writeBashCompletion(w io.Writer, fn, command string, flags []flagDef) {
+	var words []string
+	cases := map[string][]string{}
+	for _, f := range flags {
+		words = append(words, "-"+f.name)
+		if f.kind != "b" {
+			c := f.compgen()
+			cases[c] = append(cases[c], "-"+f.name, "--"+f.name)
+		}
+	}
+	fmt.Fprintf(w, "# bash completion for %s\n", command)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintln(w, `  local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}`)
+	// -name=value is split at '=' by COMP_WORDBREAKS.
+	fmt.Fprintln(w, `  if [ "$prev" = = ]; then`)
+	fmt.Fprintln(w, `    prev=${COMP_WORDS[COMP_CWORD-2]}`)
+	fmt.Fprintln(w, `  elif [ "$cur" = = ]; then`)
+	fmt.Fprintln(w, `    cur=`)
+	fmt.Fprintln(w, `  fi`)
+	if len(cases) > 0 {
+		fmt.Fprintln(w, `  case $prev in`)
+		for _, c := range []string{"file", "dir", ""} {
+			if len(cases[c]) == 0 {
+				continue
+			}
+			reply := map[string]string{"file": `$(compgen -f -- "$cur")`, "dir": `$(compgen -d -- "$cur")`}[c]
+			fmt.Fprintf(w, "    %s) COMPREPLY=(%s); return ;;\n", strings.Join(cases[c], " | "), reply)
+		}
+		fmt.Fprintln(w, `  esac`)
+	}
+	fmt.Fprintln(w, `  case $cur in`)
+	fmt.Fprintf(w, "    -*) COMPREPLY=($(compgen -W %s -- \"$cur\")) ;;\n", quoteShellString(strings.Join(words, " ")))
+	fmt.Fprintln(w, `    *) COMPREPLY=($(compgen -f -- "$cur")) ;;`)
+	fmt.Fprintln(w, `  esac`)
+	fmt.Fprintln(w, `}`)
+	fmt.Fprintf(w, "complete -o filenames -F %s %s\n", fn, command)
+}
+
+func writeZshCompletion(w io.Writer, fn, command string, flags []flagDef) {
+	escape := strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`, ":", `\:`)
+	fmt.Fprintf(w, "#compdef %s\n", command)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintln(w, "  _arguments \\")
+	for _, f := range flags {
+		name, usage := f.argName()
+		usage, _, _ = strings.Cut(usage, "\n")
+		spec := "'[" + escape.Replace(usage) + "]"
+		if f.kind != "b" {
+			spec += ":" + escape.Replace(name) + ":" + map[string]string{"file": "_files", "dir": "_files -/"}[f.compgen()]
+		}
+		fmt.Fprintf(w, "    '(-%[1]s --%[1]s)'{-%[1]s,--%[1]s}%[2]s' \\\n", f.name, spec)
+	}
+	fmt.Fprintln(w, "    '*:file:_files'")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintf(w, "compdef %s %s\n", fn, command)
+}
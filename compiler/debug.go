@@ -0,0 +1,71 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+	"text/scanner"
+)
+
+// LineComments writes the Go source position of each statement as a comment: "# file.go:42".
+var LineComments = false
+
+// Debug prints the failed command and the Go function stack when a command fails. It implies LineComments.
+var Debug = false
+
+// Trace prints the Go source lines to stderr as they run.
+var Trace = false
+
+// annotate writes the position of the statement at the current token.
+// trace is false for the declarations which are not run as statements, e.g. functions.
+func (s *state) annotate(trace bool) {
+	if LineComments || Debug {
+		s.Writeln(fmt.Sprintf("# %s:%d", s.Filename, s.Position.Line))
+	}
+	if Trace && trace && s.Position.Offset < len(s.src) {
+		start := strings.LastIndexByte(s.src[:s.Position.Offset], '\n') + 1
+		line, _, _ := strings.Cut(s.src[start:], "\n")
+		s.Writeln("printf '+ %s\\n' " + quoteShellString(fmt.Sprintf("%s:%d: %s", s.Filename, s.Position.Line, strings.TrimSpace(line))) + " >&2")
+	}
+}
+
+// Checked panics like Go on out of range indexes, integer division by zero and nil pointer dereferences.
+var Checked = false
+
+// callsPanic reports whether the Go source calls panic().
+func callsPanic(src string) bool {
+	var sc scanner.Scanner
+	sc.Init(strings.NewReader(src))
+	sc.Error = func(*scanner.Scanner, string) {}
+	for tok, prev := sc.Scan(), ""; tok != scanner.EOF; prev, tok = sc.TokenText(), sc.Scan() {
+		if tok == '(' && prev == "panic" {
+			return true
+		}
+	}
+	return false
+}
+
+// check runs the runtime check before the statement. The function returns if it panics,
+// as the statement may be a condition where the ERR trap doesn't run.
+// Checks after && or || are not run to keep the short-circuit evaluation, e.g. i < len(a) && a[i] > 0.
+func (s *state) check(name string, args ...string) {
+	if !Checked || s.shortCircuit {
+		return
+	}
+	s.useRuntime("panic.raise") // sets the ERR trap
+	s.pre = append(s.pre, s.useRuntime(name)+` "`+strings.Join(args, `" "`)+`" || return 2; `)
+}
+
+// checkIndex checks the index or the slice expression of the slice or the string.
+func (s *state) checkIndex(name string, t Type, idx []*shExpression) {
+	length, kind := "${#"+varName(name)+"}", "length"
+	if s.IsType(t, TYPE_ARRAY) {
+		length, kind = "${#"+varName(name)+"[@]}", "capacity"
+	} else if t != "string" {
+		return // maps don't panic
+	}
+	if len(idx) == 1 {
+		s.check("panic.index", idx[0].AsValue(), length)
+	} else if len(idx) >= 2 {
+		s.check("panic.slice", idx[0].AsValue(), idx[1].AsValue(), length, kind)
+	}
+}
@@ -0,0 +1,120 @@
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/scanner"
+)
+
+// FloatBackend is the command which evaluates float expressions: bc, awk, auto to use bc if it is installed and awk otherwise,
+// or coproc to send all expressions to a bc process started at the beginning of the script.
+var FloatBackend = "bc"
+
+// bcFuncs are bc functions for the math package. Functions which call others are placed after them.
+var bcFuncs = []struct{ name, def string }{
+	{"go_trunc", "define go_trunc(x) {\nauto s, y\ns = scale; scale = 0; y = x / 1; scale = s\nreturn (y)\n}"},
+	{"go_floor", "define go_floor(x) {\nauto y\ny = go_trunc(x)\nif (y > x) y = y - 1\nreturn (y)\n}"},
+	{"go_ceil", "define go_ceil(x) {\nauto y\ny = go_trunc(x)\nif (y < x) y = y + 1\nreturn (y)\n}"},
+	{"go_round", "define go_round(x) {\nif (x < 0) return (-go_floor(-x + .5))\nreturn (go_floor(x + .5))\n}"},
+	{"go_abs", "define go_abs(x) {\nif (x < 0) return (-x)\nreturn (x)\n}"},
+	{"go_mod", "define go_mod(x, y) {\nauto s, q\ns = scale; scale = 0; q = x / y; scale = s\nreturn (x - q * y)\n}"},
+	{"go_max", "define go_max(x, y) {\nif (x > y) return (x)\nreturn (y)\n}"},
+	{"go_min", "define go_min(x, y) {\nif (x < y) return (x)\nreturn (y)\n}"},
+	{"go_pow", "define go_pow(x, y) {\nif (go_trunc(y) == y) return (x ^ go_trunc(y))\nif (x == 0) return (0)\nreturn (e(y * l(x)))\n}"},
+	{"go_log2", "define go_log2(x) {\nreturn (l(x) / l(2))\n}"},
+	{"go_log10", "define go_log10(x) {\nreturn (l(x) / l(10))\n}"},
+	{"go_cbrt", "define go_cbrt(x) {\nif (x == 0) return (0)\nif (x < 0) return (-e(l(-x) / 3))\nreturn (e(l(x) / 3))\n}"},
+	{"go_tan", "define go_tan(x) {\nreturn (s(x) / c(x))\n}"},
+	{"go_asin", "define go_asin(x) {\nif (x == 1) return (2 * a(1))\nif (x == -1) return (-2 * a(1))\nreturn (a(x / sqrt(1 - x * x)))\n}"},
+	{"go_acos", "define go_acos(x) {\nreturn (2 * a(1) - go_asin(x))\n}"},
+	{"go_atan2", "define go_atan2(y, x) {\nif (x > 0) return (a(y / x))\nif (x < 0 && y < 0) return (a(y / x) - 4 * a(1))\nif (x < 0) return (a(y / x) + 4 * a(1))\nif (y > 0) return (2 * a(1))\nif (y < 0) return (-2 * a(1))\nreturn (0)\n}"},
+	{"go_sinh", "define go_sinh(x) {\nreturn ((e(x) - e(-x)) / 2)\n}"},
+	{"go_cosh", "define go_cosh(x) {\nreturn ((e(x) + e(-x)) / 2)\n}"},
+	{"go_tanh", "define go_tanh(x) {\nreturn ((e(x) - e(-x)) / (e(x) + e(-x)))\n}"},
+}
+
+// bcScript prepends the definitions of the bc functions which expr calls.
+func bcScript(expr string) string {
+	used := map[string]bool{}
+	for text := expr; text != ""; {
+		deps := ""
+		for _, f := range bcFuncs {
+			if !used[f.name] && strings.Contains(text, f.name+"(") {
+				used[f.name] = true
+				deps += f.def
+			}
+		}
+		text = deps
+	}
+	script := ""
+	for _, f := range bcFuncs {
+		if used[f.name] {
+			script += f.def + "\n"
+		}
+	}
+	return script + expr
+}
+
+// usesInfNaN reports whether the source refers to the functions returning Inf or NaN.
+// Float values are stored as "+Inf", "-Inf" and "NaN" like Go, which bc can't read.
+func usesInfNaN(src string) bool {
+	var sc scanner.Scanner
+	sc.Init(strings.NewReader(src))
+	sc.Error = func(*scanner.Scanner, string) {}
+	for tok := sc.Scan(); tok != scanner.EOF; tok = sc.Scan() {
+		switch sc.TokenText() {
+		case "Inf", "NaN", "ParseFloat":
+			return true
+		}
+	}
+	return false
+}
+
+// floatExpr returns the shell expression which evaluates a float expression.
+// Expressions are written in bc syntax. The float.awk runtime defines the bc functions for awk.
+// The precision set by shell.SetFloatPrecision is used only by bc.
+// If the program may use Inf or NaN, the runtimes evaluate the expressions including them by awk instead of bc.
+func (s *state) floatExpr(expr string) string {
+	script, env := bcScript(expr), ""
+	if s.floatPrecision >= 0 {
+		script = "scale=" + strconv.Itoa(s.floatPrecision) + ";" + script
+		env = "BC_LINE_LENGTH=" + strconv.Itoa(s.floatPrecision+10) + " "
+	}
+	switch FloatBackend {
+	case "coproc":
+		// expressions are usually hoisted (see floatCall). This reads the value in a subshell.
+		return `$(` + s.floatCall(expr) + `; echo "$GOTOSH_RET_0")`
+	case "awk":
+		return `$(` + s.useRuntime("float.awk") + ` "` + expr + `")`
+	case "auto":
+		return `$(` + env + s.useRuntime("float.eval") + ` "` + script + `" "` + expr + `")`
+	}
+	if s.infNaN {
+		return `$(` + env + s.useRuntime("float.eval") + ` "` + script + `" "` + expr + `")`
+	}
+	return `$(echo "` + script + `" | ` + env + `bc -l)`
+}
+
+// floatCall returns the command which sets GOTOSH_RET_0 to the value of a float expression by the bc coprocess.
+// The command doesn't need a subshell, so hoist calls it before the statement.
+func (s *state) floatCall(expr string) string {
+	// the bc process keeps the scale of the previous expression.
+	script := "scale=20;" + bcScript(expr)
+	if s.floatPrecision >= 0 {
+		script = "scale=" + strconv.Itoa(s.floatPrecision) + ";" + bcScript(expr)
+	}
+	if s.infNaN {
+		s.useRuntime("float.awk")
+		return s.useRuntime("float.bc") + ` "` + script + `" "` + expr + `"`
+	}
+	return s.useRuntime("float.bc") + ` "` + script + `"`
+}
+
+func checkFloatBackend() error {
+	switch FloatBackend {
+	case "bc", "awk", "auto", "coproc":
+		return nil
+	}
+	return fmt.Errorf("unsupported float backend: %s", FloatBackend)
+}
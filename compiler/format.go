@@ -0,0 +1,422 @@
+package compiler
+
+import (
+	"strconv"
+	"strings"
+)
+
+// quoteShellString quotes s as a single shell word.
+func quoteShellString(s string) string {
+	if !strings.ContainsAny(s, "'\a\b\f\n\r\t\v") {
+		return "'" + s + "'"
+	}
+	q := strconv.Quote(s)
+	return "$'" + strings.ReplaceAll(q[1:len(q)-1], "'", "\\'") + "'"
+}
+
+// simpleCommand reports whether cmd is a single command without redirections, so that printf can expand its words.
+func simpleCommand(cmd string) bool {
+	var open []byte // quotes, $'...' as 'a', and parentheses or braces of the expansions
+	for i := 0; i < len(cmd); i++ {
+		c, top := cmd[i], byte(0)
+		if len(open) > 0 {
+			top = open[len(open)-1]
+		}
+		switch {
+		case top == '\'':
+			if c == '\'' {
+				open = open[:len(open)-1]
+			}
+		case c == '\\':
+			i++
+		case top == 'a' && c == '\'', top == '"' && c == '"', top == '(' && c == ')', top == '{' && c == '}':
+			open = open[:len(open)-1]
+		case top == 'a':
+		case c == '$' && i+1 < len(cmd) && strings.IndexByte("({'", cmd[i+1]) >= 0:
+			if i++; cmd[i] == '\'' {
+				open = append(open, 'a')
+			} else {
+				open = append(open, cmd[i])
+			}
+		case top == '"':
+		case c == '\'' || c == '"' || c == '(' && top == '(':
+			open = append(open, c)
+		case top == 0 && strings.IndexByte(";|&<>()`\n", c) >= 0:
+			return false
+		}
+	}
+	return len(open) == 0
+}
+
+// shellWord returns the value v as a single shell word. An unquoted expansion such as $f of an empty float would be no word at all.
+func shellWord(v string) string {
+	v = strings.TrimSpace(v)
+	if v != "" && !strings.ContainsAny(v, "$`*?[ \t") {
+		return v
+	} else if !strings.ContainsAny(v, `"'`) || strings.HasPrefix(v, "$(") && strings.HasSuffix(v, ")") {
+		return `"` + v + `"`
+	}
+	return v
+}
+
+// unquoteShellString decodes a string literal produced by readExpression.
+func unquoteShellString(s string) (string, bool) {
+	if len(s) >= 3 && strings.HasPrefix(s, "$'") && strings.HasSuffix(s, "'") {
+		u, err := strconv.Unquote(`"` + strings.ReplaceAll(s[2:len(s)-1], "\\'", "'") + `"`)
+		return u, err == nil
+	} else if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' && !strings.ContainsAny(strings.ReplaceAll(s[1:len(s)-1], "\\`", ""), "\"`") {
+		s = strings.NewReplacer("\\$", "$", "\\`", "`").Replace(s[1 : len(s)-1])
+		return s, !strings.ContainsAny(s, "$\\")
+	} else if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "\\'", "'"), true
+	}
+	return "", false
+}
+
+// fmtOperand is a value passed to the fmt functions.
+// desc is the value descriptor consumed by the fmt.value runtime: kind, type name and payload.
+type fmtOperand struct {
+	typ  Type
+	desc []string
+}
+
+func (o *fmtOperand) kind() string {
+	return o.desc[0]
+}
+
+// simple reports whether the operand can be printed verbatim by the shell.
+func (o *fmtOperand) simple() bool {
+	return len(o.desc) == 3 && strings.Contains("six", o.kind())
+}
+
+func (o *fmtOperand) value() string {
+	return o.desc[2]
+}
+
+// fmtKind returns the runtime kind of t:
+// s(string), i(integer), f(float), b(bool), e(error), a<elem>(slice), m<key><elem>(map), t(struct) or x(other).
+func (s *state) fmtKind(t Type) string {
+	for {
+		switch {
+		case t == "bool":
+			return "b"
+		case t == "string":
+			return "s"
+		case t == "float32" || t == "float64":
+			return "f"
+		case t == "error":
+			return "e"
+		case t == "int" || t == "StatusCode" || strings.HasPrefix(string(t), "int") || strings.HasPrefix(string(t), "uint") || t == "byte" || t == "rune":
+			return "i"
+		}
+		if s.types[t] == "" {
+			break
+		}
+		t = s.types[t]
+	}
+	elemKind := func(t Type) string {
+		if k := s.fmtKind(t); len(k) == 1 && k != "t" {
+			return k
+		}
+		return "x"
+	}
+	switch {
+	case strings.HasPrefix(string(t), TYPE_ARRAY):
+		return "a" + elemKind(t.ElementType())
+	case strings.HasPrefix(string(t), TYPE_MAP):
+		key := strings.SplitN(strings.TrimPrefix(string(t), TYPE_MAP), "]", 2)[0]
+		return "m" + elemKind(Type(key)) + elemKind(t.ElementType())
+	case strings.HasPrefix(string(t), "struct{"):
+		return "t"
+	case strings.HasPrefix(string(t), TYPE_PTR) && s.fmtKind(t[1:]) == "t":
+		return "t"
+	}
+	return "x"
+}
+
+// goTypeName returns the name of t as printed by %T.
+func goTypeName(t Type) string {
+	if !strings.Contains(string(t), "struct{") {
+		return string(t)
+	}
+	f := strings.Split(string(t), ":")
+	name := f[0][:len(f[0])-1] + " {"
+	for i := 1; i < len(f)-2; i += 2 {
+		name += " " + f[i] + " " + goTypeName(Type(f[i+1])) + ";"
+	}
+	return strings.TrimSuffix(name, ";") + " " + f[len(f)-1]
+}
+
+// structDesc builds descriptors of struct t from its flattened field values.
+func (s *state) structDesc(t Type, values *[]string) []string {
+	f := strings.Split(string(s.resolveType(Type(strings.TrimPrefix(string(t), TYPE_PTR)))), ":")
+	desc := []string{"t", quoteShellString(goTypeName(t)), strconv.Itoa(len(f)/2 - 1)}
+	for i := 1; i < len(f)-2; i += 2 {
+		desc = append(desc, f[i])
+		if ft := Type(f[i+1]); s.fmtKind(ft) == "t" && !s.IsType(ft, TYPE_PTR) {
+			desc = append(desc, s.structDesc(ft, values)...)
+		} else if len(*values) > 0 {
+			kind := s.fmtKind(ft)
+			if len(kind) > 1 || kind == "t" {
+				kind = "x"
+			}
+			desc = append(desc, kind, quoteShellString(goTypeName(ft)), (*values)[0])
+			*values = (*values)[1:]
+		}
+	}
+	return desc
+}
+
+// fmtOperands converts the arguments of fmt functions to operands.
+func (s *state) fmtOperands(args []*shExpression) []*fmtOperand {
+	var ops []*fmtOperand
+	for _, a := range args {
+		if a.expr == "" && a.values == nil {
+			continue
+		}
+		for i := range a.retTypes {
+			t := a.varType(i)
+			if i != a.primaryIdx && i != 0 {
+				ops = append(ops, &fmtOperand{t, []string{s.fmtKind(t), quoteShellString(goTypeName(t)), `"` + varValue(a.RetVarName(i)) + `"`}})
+				continue
+			}
+			ops = append(ops, &fmtOperand{t, s.fmtDesc(t, a)})
+		}
+	}
+	return ops
+}
+
+func (s *state) fmtDesc(t Type, a *shExpression) []string {
+	kind := s.fmtKind(t)
+	name := quoteShellString(goTypeName(t))
+	if _, ok := s.runtimeDefs[string(t)+".String"]; ok {
+		// fmt uses the String method of Stringer types such as time.Duration.
+		return []string{"s", name, `"$(` + s.useRuntime(string(t)+".String") + " " + a.AsValue() + `)"`}
+	}
+	v := varName(a.expr)
+	_, isVar := s.vars[v]
+	switch kind[0] {
+	case 'a':
+		if a.expr == "" && a.values != nil {
+			desc := []string{kind, name, strconv.Itoa(len(a.values))}
+			for _, v := range a.values {
+				desc = append(desc, shellWord(v))
+			}
+			return desc
+		} else if isVar {
+			return []string{kind, name, "\"${#" + v + "[@]}\"", "\"${" + v + "[@]}\""}
+		} else if v, ok := strings.CutPrefix(a.AsValue(), `"${`); ok && strings.HasSuffix(v, `[@]}"`) && !strings.ContainsAny(v, "$ ") {
+			// global arrays such as flag.Args()
+			return []string{kind, name, "\"${#" + strings.TrimSuffix(v, `[@]}"`) + "[@]}\"", a.AsValue()}
+		}
+		return []string{"x", name, `"[` + strings.Trim(a.AsValue(), `"`) + `]"`}
+	case 'm':
+		if a.expr == "" && a.values != nil {
+			desc := []string{kind, name, strconv.Itoa(len(a.values) / 2)}
+			for i := 0; i < len(a.values)-1; i += 2 {
+				desc = append(desc, shellWord(a.values[i]))
+			}
+			for i := 1; i < len(a.values); i += 2 {
+				desc = append(desc, shellWord(a.values[i]))
+			}
+			return desc
+		} else if isVar {
+			return []string{kind, name, "\"${#" + v + "[@]}\"", "\"${!" + v + "[@]}\"", "\"${" + v + "[@]}\""}
+		}
+	case 't':
+		var values []string
+		if s.IsType(t, TYPE_PTR) && isVar {
+			for _, f := range s.fields(Type(strings.TrimPrefix(string(t), TYPE_PTR)), v) {
+				values = append(values, `"`+varValue(varName(f.Name))+`"`)
+			}
+		} else {
+			values = a.Values()
+		}
+		if len(values) == len(s.fields(Type(strings.TrimPrefix(string(t), TYPE_PTR)), "")) {
+			return s.structDesc(t, &values)
+		}
+	}
+	if len(kind) > 1 || kind == "t" {
+		kind = "x"
+	}
+	value := a.AsValue()
+	if n, err := strconv.ParseInt(strings.NewReplacer("8#", "0o", "2#", "0b").Replace(value), 0, 64); err == nil && kind == "i" {
+		value = strconv.FormatInt(n, 10)
+	}
+	return []string{kind, name, shellWord(value)}
+}
+
+// fmtFormat returns the format string equivalent to Print (sep="") or Println (sep=" ").
+func fmtFormat(ops []*fmtOperand, sep, end string) string {
+	f := ""
+	for i, op := range ops {
+		if i > 0 && (sep != "" || op.kind() != "s" && ops[i-1].kind() != "s") {
+			f += " "
+		}
+		f += "%v"
+	}
+	return f + end
+}
+
+// fmtFastFormat returns the format for the builtin printf if all verbs in f can be handled by it.
+func fmtFastFormat(f string, ops []*fmtOperand) (string, bool) {
+	out := ""
+	n := 0
+	for {
+		p := strings.IndexByte(f, '%')
+		if p < 0 {
+			break
+		}
+		out += f[:p+1]
+		f = f[p+1:]
+		spec := strings.TrimLeft(f, "-+ 0123456789.")
+		if spec == "" {
+			return "", false
+		}
+		out += f[:len(f)-len(spec)]
+		verb := spec[0]
+		f = spec[1:]
+		if verb == '%' {
+			out += "%"
+			continue
+		} else if n >= len(ops) || !ops[n].simple() && ops[n].kind() != "f" {
+			return "", false
+		}
+		switch kind := ops[n].kind(); {
+		case verb == 'v' && kind != "f", verb == 's' && kind != "i" && kind != "f":
+			out += "s"
+		case verb == 'd' && kind == "i", strings.IndexByte("eEf", verb) >= 0 && kind == "f":
+			out += string(verb)
+		default:
+			return "", false
+		}
+		n++
+	}
+	return out + f, n == len(ops)
+}
+
+func (s *state) fmtValues(ops []*fmtOperand) []string {
+	var values []string
+	for _, op := range ops {
+		values = append(values, op.desc...)
+	}
+	return values
+}
+
+// fmtPrint implements Print, Println and Printf.
+func (s *state) fmtPrint(e *shExpression, f string, ops []*fmtOperand) {
+	if format, ok := fmtFastFormat(f, ops); ok {
+		var values []string
+		for _, op := range ops {
+			values = append(values, op.value())
+		}
+		format = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\t", "\\t", "\r", "\\r").Replace(format)
+		e.expr = strings.Join(append([]string{"printf", quoteShellString(format)}, values...), " ")
+		return
+	}
+	e.expr = strings.Join(append([]string{s.useRuntime("fmt.printf"), quoteShellString(f)}, s.fmtValues(ops)...), " ")
+}
+
+// fmtSprint implements Sprint, Sprintln and Sprintf.
+func (s *state) fmtSprint(e *shExpression, format string, ops []*fmtOperand) {
+	e.retTypes = []Type{"string"}
+	e.expr = strings.Join(append([]string{s.useRuntime("fmt.sprintf"), format}, s.fmtValues(ops)...), " ")
+	e.primaryIdx = -1
+}
+
+// fmtConcat returns a shell word that concatenates simple operands as Sprint or Sprintln.
+func fmtConcat(ops []*fmtOperand, sep, end string) (string, bool) {
+	word := ""
+	for i, op := range ops {
+		if !op.simple() {
+			return "", false
+		}
+		if i > 0 && (sep != "" || op.kind() != "s" && ops[i-1].kind() != "s") {
+			word += `" "`
+		}
+		word += op.value()
+	}
+	if end != "" {
+		word += quoteShellString(end)
+	}
+	if word == "" {
+		word = `""`
+	}
+	return word, true
+}
+
+func (s *state) fmtFuncs() map[string]shExpression {
+	print := func(sep, end string) func(e *shExpression, args []*shExpression) {
+		return func(e *shExpression, args []*shExpression) {
+			ops := s.fmtOperands(args)
+			s.fmtPrint(e, fmtFormat(ops, sep, end), ops)
+		}
+	}
+	fprint := func(p func(e *shExpression, args []*shExpression)) func(e *shExpression, args []*shExpression) {
+		return func(e *shExpression, args []*shExpression) {
+			p(e, args[1:])
+			if args[0].typ != "VALUE" && args[0].retTypes[0] == "io.Writer" && simpleCommand(e.expr) {
+				// may be io.MultiWriter
+				e.expr = s.useRuntime("io.write") + " " + shellWord(args[0].AsValue()) + " " + e.expr
+				return
+			}
+			e.expr += " >&" + args[0].AsValue()
+		}
+	}
+	printf := func(e *shExpression, args []*shExpression) {
+		ops := s.fmtOperands(args[1:])
+		if f, ok := unquoteShellString(args[0].AsValue()); ok {
+			s.fmtPrint(e, f, ops)
+		} else {
+			e.expr = strings.Join(append([]string{s.useRuntime("fmt.printf"), args[0].AsValue()}, s.fmtValues(ops)...), " ")
+		}
+	}
+	sprint := func(sep, end string) func(e *shExpression, args []*shExpression) {
+		return func(e *shExpression, args []*shExpression) {
+			ops := s.fmtOperands(args)
+			if word, ok := fmtConcat(ops, sep, end); ok {
+				e.expr = word
+				e.typ = "VALUE"
+				e.retTypes = []Type{"string"}
+				return
+			}
+			s.fmtSprint(e, quoteShellString(fmtFormat(ops, sep, end)), ops)
+		}
+	}
+	sprintf := func(e *shExpression, args []*shExpression) {
+		s.fmtSprint(e, args[0].AsValue(), s.fmtOperands(args[1:]))
+	}
+	scan := func(name string, inputs int) func(e *shExpression, args []*shExpression) {
+		return func(e *shExpression, args []*shExpression) {
+			words := []string{s.useRuntime(name)}
+			for i, a := range args {
+				if a.expr == "" {
+					continue
+				} else if i < inputs {
+					words = append(words, a.AsValue())
+				} else if kind := s.fmtKind(Type(strings.TrimPrefix(string(a.retTypes[0]), TYPE_PTR))); len(kind) == 1 && kind != "t" && kind != "x" {
+					words = append(words, kind, a.AsValue())
+				} else {
+					words = append(words, "s", a.AsValue())
+				}
+			}
+			e.expr = strings.Join(words, " ")
+		}
+	}
+	scanRet := []Type{"int", "StatusCode"}
+	return map[string]shExpression{
+		"fmt.Print":    {applyFunc2: print("", "")},
+		"fmt.Println":  {applyFunc2: print(" ", "\n")},
+		"fmt.Printf":   {applyFunc2: printf},
+		"fmt.Sprint":   {retTypes: []Type{"string"}, applyFunc2: sprint("", "")},
+		"fmt.Sprintln": {retTypes: []Type{"string"}, applyFunc2: sprint(" ", "\n")},
+		"fmt.Sprintf":  {retTypes: []Type{"string"}, applyFunc2: sprintf},
+		"fmt.Fprint":   {applyFunc2: fprint(print("", ""))},
+		"fmt.Fprintln": {applyFunc2: fprint(print(" ", "\n"))},
+		"fmt.Fprintf":  {applyFunc2: fprint(printf)},
+		"fmt.Scan":     {retTypes: scanRet, primaryIdx: -1, applyFunc2: scan("fmt.scan", 0)},
+		"fmt.Scanln":   {retTypes: scanRet, primaryIdx: -1, applyFunc2: scan("fmt.scanln", 0)},
+		"fmt.Sscan":    {retTypes: scanRet, primaryIdx: -1, applyFunc2: scan("fmt.sscan", 1)},
+		"fmt.Sscanf":   {retTypes: scanRet, primaryIdx: -1, applyFunc2: scan("fmt.sscanf", 2)},
+	}
+}
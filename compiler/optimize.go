@@ -0,0 +1,303 @@
+package compiler
+
+import (
+	"bytes"
+	"path"
+	"slices"
+	"strings"
+	"text/scanner"
+)
+
+// Optimize drops unused functions and package-level variables, and inlines small functions at their call sites.
+// Functions called only from shell code (e.g. shell.Do) are dropped as well.
+var Optimize = false
+
+// maxInlineTokens is the maximum number of tokens in the body of a function to be inlined.
+const maxInlineTokens = 24
+
+// chunk is the output of a top-level declaration, dropped if the declared names are not used.
+type chunk struct {
+	text    bytes.Buffer
+	decls   []string        // shell function names and "$" + variable names
+	uses    map[string]bool // same as decls, runtime functions included
+	calls   bool            // the variable initializer calls a function
+	comment bool            // comments are kept with the following declaration
+}
+
+// inlineFunc is a function consisting of a return statement or a function call, expanded at its call sites.
+type inlineFunc struct {
+	body    string
+	void    bool
+	params  []string
+	refs    []inlineRef
+	globals []string          // variables of the main package which may be shadowed at the call site
+	imports map[string]string // packages used in the body
+}
+
+// inlineRef is an identifier in the body replaced by an argument or a qualified name.
+type inlineRef struct {
+	offset, end int
+	param       int
+	name        string
+}
+
+// beginChunk starts the chunk of the next top-level declaration.
+func (s *state) beginChunk(comment bool) {
+	if c := s.chunk; c != nil && c.comment && len(c.decls) == 0 && c.uses == nil {
+		c.comment = comment
+		return
+	}
+	s.chunk = &chunk{comment: comment}
+	s.chunks = append(s.chunks, s.chunk)
+	s.w = &s.chunk.text
+}
+
+// use records a function or a variable used in the current chunk.
+func (s *state) use(name string) {
+	if s.chunk == nil {
+		return
+	}
+	if s.chunk.uses == nil {
+		s.chunk.uses = map[string]bool{}
+	}
+	s.chunk.uses[name] = true
+}
+
+// useVar records a variable and the variables containing it. e.g. "a.b.c" uses "a.b" and "a".
+func (s *state) useVar(name string) {
+	for p := len(name); p > 0; p = strings.LastIndex(name[:p], ".") {
+		s.use("$" + name[:p])
+	}
+}
+
+func (s *state) declare(name string) {
+	if s.chunk != nil {
+		s.chunk.decls = append(s.chunk.decls, name)
+	}
+}
+
+// liveChunks returns the chunks reachable from main and the initializers calling functions.
+// funcUsed of the runtime functions is updated to emit only the functions used by them.
+func (s *state) liveChunks() []*chunk {
+	declared := map[string]*chunk{}
+	for _, c := range s.chunks {
+		for _, name := range c.decls {
+			declared[name] = c
+		}
+	}
+	live := map[*chunk]bool{}
+	uses := map[string]bool{}
+	var mark func(c *chunk)
+	mark = func(c *chunk) {
+		if live[c] {
+			return
+		}
+		live[c] = true
+		for name := range c.uses {
+			uses[name] = true
+			if d, ok := declared[name]; ok {
+				mark(d)
+			}
+		}
+	}
+	for _, c := range s.chunks {
+		if len(c.decls) == 0 || c.calls && strings.HasPrefix(c.decls[0], "$") || slices.Contains(c.decls, s.funcs["main.main"].expr) {
+			mark(c)
+		}
+	}
+	for name, f := range s.funcs {
+		if _, ok := s.runtimeDefs[name]; ok && f.funcUsed {
+			f.funcUsed = uses[f.expr]
+			s.funcs[name] = f
+		}
+	}
+	return slices.DeleteFunc(slices.Clone(s.chunks), func(c *chunk) bool { return !live[c] })
+}
+
+// inlineBody returns the body of the function if it can be inlined.
+// The body is a return statement or a function call using only the arguments, constants and package-level variables.
+// Functions are not inlined with --checked since the checks are run before the statement in the function.
+func (s *state) inlineBody(name string, f *shExpression, args []string, open, close int) *inlineFunc {
+	if Checked || strings.ContainsAny(name, ".") || strings.HasPrefix(name, "GOTOSH_") || len(f.retTypes) > 1 || open >= close || close > len(s.src) {
+		return nil
+	}
+	for _, t := range append(slices.Clone(f.argTypes), f.retTypes...) {
+		if _, ok := specialReturnTypes[t]; ok || len(s.fields(t, "")) != 1 || s.IsType(t, TYPE_PTR) || s.IsType(t, TYPE_ARRAY) || s.IsType(t, TYPE_MAP) || s.IsType(t, "func(") {
+			return nil
+		}
+	}
+	type token struct {
+		tok         rune
+		text        string
+		offset, end int
+		prev, next  rune
+	}
+	var sc scanner.Scanner
+	sc.Init(strings.NewReader(s.src[open+1 : close]))
+	sc.Error = func(*scanner.Scanner, string) {}
+	var toks []token
+	for tok := sc.Scan(); tok != scanner.EOF; tok = sc.Scan() {
+		toks = append(toks, token{tok: tok, text: sc.TokenText(), offset: sc.Position.Offset, end: sc.Position.Offset + len(sc.TokenText())})
+	}
+	if len(toks) < 2 || len(toks) > maxInlineTokens {
+		return nil
+	}
+	for i := range toks {
+		if i > 0 {
+			toks[i].prev = toks[i-1].tok
+		}
+		if i+1 < len(toks) {
+			toks[i].next = toks[i+1].tok
+		}
+	}
+	inl := &inlineFunc{void: len(f.retTypes) == 0, params: args, imports: map[string]string{}}
+	body := toks
+	if inl.void {
+		// a single function call: name(...)
+		if toks[0].tok != scanner.Ident || toks[len(toks)-1].tok != ')' {
+			return nil
+		}
+		depth := 0
+		for i, t := range toks {
+			if t.tok == '(' {
+				depth++
+			} else if t.tok == ')' {
+				if depth--; depth == 0 && i != len(toks)-1 {
+					return nil
+				}
+			} else if depth == 0 && t.tok != scanner.Ident && t.tok != '.' {
+				return nil
+			}
+		}
+	} else if toks[0].text != "return" {
+		return nil
+	} else {
+		body = toks[1:]
+	}
+	inl.body = s.src[open+1+body[0].offset : open+1+body[len(body)-1].end]
+	if strings.Contains(inl.body, "\n") {
+		return nil
+	}
+	base := body[0].offset
+	for _, t := range body {
+		if strings.ContainsRune(";:{}", t.tok) || t.tok == '=' && t.next != '=' && !strings.ContainsRune("=!<>", t.prev) || t.tok == '<' && t.next == '-' {
+			return nil // statements
+		}
+		if t.tok != scanner.Ident || t.prev == '.' {
+			continue
+		}
+		ref := inlineRef{offset: t.offset - base, end: t.end - base, param: slices.Index(args, t.text)}
+		qualified := s.packageName + "." + t.text
+		if t.text == name || t.text == "func" || t.text == "go" || t.text == "defer" {
+			return nil // recursive or statements
+		} else if ref.param >= 0 && t.next == '.' {
+			return nil // method call on the argument
+		} else if ref.param >= 0 {
+			inl.refs = append(inl.refs, ref)
+		} else if t.next == '.' && s.imports[t.text] != "" && s.vars[t.text].Type == "" {
+			inl.imports[t.text] = s.imports[t.text]
+		} else if t.next == '(' && s.packageName != "main" && s.funcs[qualified].expr != "" {
+			ref.name = qualified
+			inl.refs = append(inl.refs, ref)
+		} else if t.next == '(' && s.vars[t.text].Type == "" {
+			// function call or conversion
+		} else if s.packageName != "main" && s.vars[qualified].Type != "" {
+			ref.name = qualified
+			inl.refs = append(inl.refs, ref)
+		} else if s.packageName == "main" && s.vars[t.text].Type != "" {
+			inl.globals = append(inl.globals, t.text)
+		} else if t.text != "true" && t.text != "false" && t.text != "nil" {
+			return nil
+		}
+	}
+	return inl
+}
+
+// inlineCall expands the function at the call site. The arguments are read from the source and
+// substituted in the body, so the function must be called with the arguments without side effects.
+// It returns nil without reading the arguments if the function can't be inlined here.
+func (s *state) inlineCall(name string, inl *inlineFunc) *shExpression {
+	if s.inlining[name] || s.lastToken != '(' {
+		return nil
+	}
+	for _, g := range inl.globals {
+		if s.locals[g] {
+			return nil
+		}
+	}
+	for alias, pkg := range inl.imports {
+		if s.imports[alias] != pkg && (s.imports[alias] != "" || path.Base(pkg) != alias) {
+			return nil
+		}
+	}
+	// split the arguments in the source
+	base := s.Position.Offset
+	var sc scanner.Scanner
+	sc.Init(strings.NewReader(s.src[base:]))
+	sc.Error = func(*scanner.Scanner, string) {}
+	var args []string
+	var simple []bool
+	depth, ntok, start, argToks := 0, 0, 0, 0
+	for tok := sc.Scan(); tok != scanner.EOF && (depth > 0 || ntok == 0); tok = sc.Scan() {
+		ntok++
+		if tok == '(' && depth == 0 {
+			depth, start = 1, sc.Position.Offset+1
+		} else if tok == ',' || tok == ')' {
+			if argToks > 0 {
+				args = append(args, strings.TrimSpace(s.src[base+start:base+sc.Position.Offset]))
+				simple = append(simple, argToks == 1)
+			}
+			start, argToks = sc.Position.Offset+1, 0
+			if tok == ')' {
+				depth = 0
+			}
+		} else if tok == '(' || tok == '{' || tok == '<' && sc.Peek() == '-' || sc.Position.Line > 1 || strings.Contains(sc.TokenText(), "\n") {
+			return nil // calls may have side effects, and the expression must be in a line
+		} else {
+			argToks++
+		}
+	}
+	if depth != 0 || len(args) != len(inl.params) {
+		return nil
+	}
+	counts := make([]int, len(args))
+	for _, ref := range inl.refs {
+		if ref.param >= 0 {
+			counts[ref.param]++
+		}
+	}
+	for i, n := range counts {
+		if n > 1 && !simple[i] {
+			return nil // evaluated only once
+		}
+	}
+	text, pos := "", 0
+	for _, ref := range inl.refs {
+		text += inl.body[pos:ref.offset]
+		if ref.param < 0 {
+			text += ref.name
+		} else if simple[ref.param] {
+			text += args[ref.param]
+		} else {
+			text += "(" + args[ref.param] + ")"
+		}
+		pos = ref.end
+	}
+	text += inl.body[pos:]
+	for i := 0; i < ntok; i++ {
+		s.Scan()
+	}
+
+	saved, lastToken := s.Scanner, s.lastToken
+	s.Scanner = scanner.Scanner{}
+	s.Init(strings.NewReader(text))
+	s.Filename, s.Line = saved.Filename, 1 // readExpression reads until the end of the line
+	s.inlining[name] = true
+	e := s.readExpression("", "", false)
+	delete(s.inlining, name)
+	s.Scanner, s.lastToken, s.skipNextScan = saved, lastToken, false
+	if !inl.void {
+		e.retTypes = slices.Clone(s.funcs[name].retTypes)
+	}
+	return e
+}
@@ -0,0 +1,265 @@
+package compiler
+
+import (
+	"errors"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ereWriter translates RE2 syntax to POSIX ERE.
+// ERE has no non-capturing groups, so groups[i] keeps the ERE group of the Go group i.
+type ereWriter struct {
+	b      strings.Builder
+	ngroup int
+	groups []string
+	flags  string
+}
+
+// translateRegexp converts an RE2 pattern to "flags:groups:ERE" used by the regexp runtime.
+// flags has ^ or $ if all matches are anchored to the start or the end of the text.
+// groups is a comma separated list of the ERE groups of Go groups, followed by =name for named groups.
+func translateRegexp(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	w := &ereWriter{groups: make([]string, re.MaxCap()+1)}
+	w.groups[0] = "0"
+	subs := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		subs = re.Sub
+	}
+	if len(subs) > 0 && subs[0].Op == syntax.OpBeginText {
+		w.flags += "^"
+		w.b.WriteString("^")
+		subs = subs[1:]
+	}
+	end := len(subs) > 0 && subs[len(subs)-1].Op == syntax.OpEndText
+	if end {
+		subs = subs[:len(subs)-1]
+	}
+	if len(subs) == 0 {
+		w.group(&syntax.Regexp{Op: syntax.OpEmptyMatch})
+	} else if len(subs) == 1 && w.flags == "" && !end {
+		if err := w.write(re); err != nil {
+			return "", err
+		}
+		subs = nil
+	}
+	for _, sub := range subs {
+		if err := w.concatItem(sub); err != nil {
+			return "", err
+		}
+	}
+	if end {
+		w.flags += "$"
+		w.b.WriteString("$")
+	}
+	return w.flags + ":" + strings.Join(w.groups, ",") + ":" + w.b.String(), nil
+}
+
+// group writes re in a capturing group which is not a Go group.
+func (w *ereWriter) group(re *syntax.Regexp) error {
+	w.ngroup++
+	w.b.WriteString("(")
+	if re.Op != syntax.OpEmptyMatch {
+		if err := w.write(re); err != nil {
+			return err
+		}
+	}
+	w.b.WriteString(")")
+	return nil
+}
+
+func (w *ereWriter) concatItem(re *syntax.Regexp) error {
+	if re.Op == syntax.OpAlternate || re.Op == syntax.OpEmptyMatch {
+		return w.group(re)
+	}
+	return w.write(re)
+}
+
+func (w *ereWriter) atom(re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpCapture, syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return w.write(re)
+	case syntax.OpLiteral:
+		if len(re.Rune) == 1 {
+			return w.write(re)
+		}
+	}
+	return w.group(re)
+}
+
+func (w *ereWriter) write(re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return errors.New("pattern never matches")
+	case syntax.OpEmptyMatch:
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			w.literal(r, re.Flags&syntax.FoldCase != 0)
+		}
+	case syntax.OpCharClass:
+		return w.charClass(re.Rune)
+	case syntax.OpAnyCharNotNL:
+		w.b.WriteString("[^\n]")
+	case syntax.OpAnyChar:
+		w.b.WriteString(".")
+	case syntax.OpBeginLine, syntax.OpEndLine:
+		return errors.New("multi-line mode (?m) is not supported")
+	case syntax.OpBeginText, syntax.OpEndText:
+		return errors.New("^ and $ are supported only at the start and the end of the pattern")
+	case syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return errors.New(`\b and \B are not supported`)
+	case syntax.OpCapture:
+		w.ngroup++
+		w.groups[re.Cap] = strconv.Itoa(w.ngroup)
+		if re.Name != "" {
+			w.groups[re.Cap] += "=" + re.Name
+		}
+		w.b.WriteString("(")
+		if err := w.write(re.Sub[0]); err != nil {
+			return err
+		}
+		w.b.WriteString(")")
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		if re.Flags&syntax.NonGreedy != 0 {
+			return errors.New("non-greedy repetition is not supported")
+		}
+		if err := w.atom(re.Sub[0]); err != nil {
+			return err
+		}
+		switch re.Op {
+		case syntax.OpStar:
+			w.b.WriteString("*")
+		case syntax.OpPlus:
+			w.b.WriteString("+")
+		case syntax.OpQuest:
+			w.b.WriteString("?")
+		default:
+			if re.Max > 255 {
+				return errors.New("repetition count is larger than 255")
+			}
+			w.b.WriteString("{" + strconv.Itoa(re.Min))
+			if re.Max != re.Min {
+				w.b.WriteString(",")
+				if re.Max >= 0 {
+					w.b.WriteString(strconv.Itoa(re.Max))
+				}
+			}
+			w.b.WriteString("}")
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := w.concatItem(sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		for i, sub := range re.Sub {
+			if i > 0 {
+				w.b.WriteString("|")
+			}
+			if err := w.concatItem(sub); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.New("unsupported syntax: " + re.String())
+	}
+	return nil
+}
+
+func (w *ereWriter) literal(r rune, foldCase bool) {
+	if foldCase {
+		var rs []rune
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			rs = append(rs, f, f)
+		}
+		if len(rs) > 0 {
+			w.charClass(append(rs, r, r))
+			return
+		}
+	}
+	if strings.ContainsRune(`.[\()*+?{|^$`, r) {
+		w.b.WriteByte('\\')
+	}
+	w.b.WriteRune(r)
+}
+
+// charClass writes a bracket expression. ], ^, - and [ are placed where they are not special.
+func (w *ereWriter) charClass(ranges []rune) error {
+	negate := len(ranges) > 0 && ranges[0] == 0 && ranges[len(ranges)-1] == unicode.MaxRune
+	if negate {
+		var inv []rune
+		for i := 1; i < len(ranges)-1; i += 2 {
+			inv = append(inv, ranges[i]+1, ranges[i+1]-1)
+		}
+		ranges = inv
+	}
+	if len(ranges) > 64 {
+		return errors.New("Unicode character classes are not supported")
+	}
+	var items strings.Builder
+	writeRange := func(lo, hi rune) {
+		if lo <= hi {
+			items.WriteRune(lo)
+			if hi > lo+1 {
+				items.WriteByte('-')
+			}
+			if hi > lo {
+				items.WriteRune(hi)
+			}
+		}
+	}
+	special := map[rune]bool{}
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], 1), ranges[i+1]
+		for _, c := range "-[]^" {
+			if lo <= c && c <= hi {
+				writeRange(lo, c-1)
+				special[c] = true
+				lo = c + 1
+			}
+		}
+		writeRange(lo, hi)
+	}
+	tail := ""
+	for _, c := range "[^-" {
+		if special[c] {
+			tail += string(c)
+		}
+	}
+	if !negate && !special[']'] && items.Len() == 0 && strings.HasPrefix(tail, "^") {
+		// ^ can't be the first
+		if tail == "^" {
+			w.b.WriteString(`\^`)
+			return nil
+		}
+		tail = "-^"
+	}
+	w.b.WriteString("[")
+	if negate {
+		w.b.WriteString("^")
+	}
+	if special[']'] {
+		w.b.WriteString("]")
+	}
+	w.b.WriteString(items.String() + tail + "]")
+	return nil
+}
+
+// compileRegexp translates a pattern at compile time. Patterns which are not constants are used as POSIX ERE.
+func (s *state) compileRegexp(pattern string) string {
+	p, ok := unquoteShellString(pattern)
+	if !ok {
+		return `"::"` + pattern
+	}
+	re, err := translateRegexp(p)
+	if err != nil {
+		s.errorf("regexp %s: %v", strconv.Quote(p), err)
+	}
+	return quoteShellString(re)
+}
@@ -75,13 +75,26 @@ func (s *state) loadRuntimeDefinitions() error {
 	}
 	s.runtimeDefs = defs
 	for name, def := range defs {
-		s.funcs[name] = shExpression{
-			expr:       "GOTOSH_RT_" + strings.ReplaceAll(name, ".", "__"),
-			argTypes:   def.ArgTypes,
-			retTypes:   def.RetTypes,
-			primaryIdx: 0,
-			stdout:     true,
+		f := shExpression{
+			expr:      "GOTOSH_RT_" + strings.ReplaceAll(name, ".", "__"),
+			argTypes:  def.ArgTypes,
+			retTypes:  def.RetTypes,
+			intStatus: strings.HasPrefix(name, "shell."),
 		}
+		s.setReturnConvention(&f)
+		if f.primaryIdx >= 0 && s.IsType(f.retTypes[f.primaryIdx], TYPE_ARRAY) {
+			// Arrays are returned in GOTOSH_RET_0 to keep the elements intact.
+			f.primaryIdx = -1
+			f.stdout = false
+		}
+		f.retTypes = make([]Type, len(def.RetTypes))
+		for i, t := range def.RetTypes {
+			if special, ok := specialReturnTypes[t]; ok && t != "StatusCode" {
+				t = special
+			}
+			f.retTypes[i] = t
+		}
+		s.funcs[name] = f
 	}
 	return nil
 }
@@ -128,3 +141,15 @@ func (s *state) emitUsedRuntime() {
 		emit(name)
 	}
 }
+
+// useRuntime marks the runtime function as used and returns its shell function name.
+func (s *state) useRuntime(name string) string {
+	f, ok := s.funcs[name]
+	if !ok {
+		f.expr = "GOTOSH_RT_" + strings.ReplaceAll(name, ".", "__")
+	}
+	f.funcUsed = true
+	s.funcs[name] = f
+	s.use(f.expr)
+	return f.expr
+}
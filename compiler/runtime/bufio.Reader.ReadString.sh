@@ -0,0 +1,8 @@
+local d line=
+printf -v d '%b' "$2"
+if IFS= read -r -d "$d" line <&"$1"; then
+  GOTOSH_RET_0=$line$d
+  return 0
+fi
+GOTOSH_RET_0=$line
+return 1
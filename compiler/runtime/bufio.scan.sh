@@ -0,0 +1,23 @@
+local fd v line= word= ok=
+v=$1__fd
+fd=${!v}
+v=$1__split
+if [ "${!v}" = 1 ]; then
+  v=$1__rest
+  line=${!v}
+  while line=${line#"${line%%[![:space:]]*}"}; [ -z "$line" ]; do
+    IFS= read -r line <&"$fd" || [ -n "$line" ] || break
+  done
+  if [ -n "$line" ]; then
+    word=${line%%[[:space:]]*}
+    ok=1
+  fi
+  printf -v "$1__rest" '%s' "${line#"$word"}"
+elif IFS= read -r line <&"$fd" || [ -n "$line" ]; then
+  word=${line%$'\r'}
+  ok=1
+fi
+printf -v "$1__text" '%s' "$word"
+[ -z "$ok" ] || return 0
+{ : <&"$fd"; } 2>/dev/null || printf -v "$1__err" '%s' 9
+return 1
@@ -0,0 +1,3 @@
+# read is interrupted when a trapped signal arrives. The trap handler may have sent the value, so read again.
+# A signal caught just before read blocks is handled when the read times out.
+until IFS= read -r -t 1 GOTOSH_RET_0 <&"$1"; do :; done
@@ -0,0 +1,12 @@
+# Failures in the runtime functions, the status returned in $(f) and panics are reported by the caller.
+if [[ ${FUNCNAME[1]} == GOTOSH_RT_* || $BASH_SUBSHELL -gt 0 && $3 == return* || -n ${GOTOSH_PANIC+x} ]]; then
+  return 0
+fi
+# A function returns the status of the failed command, which is reported once, not again by each caller.
+if [[ $1 -eq ${GOTOSH_DEBUG_ERR-} && $3 == "${GOTOSH_DEBUG_CMD-}" && ${#FUNCNAME[@]} -lt ${GOTOSH_DEBUG_DEPTH:-0} ]]; then
+  GOTOSH_DEBUG_DEPTH=${#FUNCNAME[@]}
+  return 0
+fi
+GOTOSH_DEBUG_ERR=$1 GOTOSH_DEBUG_CMD=$3 GOTOSH_DEBUG_DEPTH=${#FUNCNAME[@]}
+printf 'error: exit status %d: %s\n' "$1" "$3" >&2
+GOTOSH_RT_debug__stack 2 "$2" >&2
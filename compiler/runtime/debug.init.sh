@@ -0,0 +1,3 @@
+# The script is read to map the lines to the Go positions, so the path is resolved before os.Chdir().
+GOTOSH_SCRIPT=${BASH_SOURCE[0]}
+[[ -z $GOTOSH_SCRIPT || $GOTOSH_SCRIPT == /* ]] || GOTOSH_SCRIPT=$PWD/$GOTOSH_SCRIPT
@@ -0,0 +1,22 @@
+# Prints the Go function stack from the frame $1 running the line $2, like the goroutine trace of Go.
+# The Go positions are taken from the nearest "# file.go:line" comment written before the lines by --lines or --debug,
+# or the positions in the script are printed.
+local i lines=$2 names= src=${GOTOSH_SCRIPT:-${BASH_SOURCE[0]}}
+for (( i = $1; i < ${#FUNCNAME[@]} - 1; i++ )); do
+  [ "$i" -eq "$1" ] || lines="$lines ${BASH_LINENO[i-1]}"
+  names="$names ${FUNCNAME[i]}"
+done
+[ -f "$src" ] || src=/dev/null
+awk -v lines="$lines" -v names="$names" '
+BEGIN { n = split(lines, line, " "); split(names, name, " ") }
+/^ *# [^ ]+\.go:[0-9]+$/ { pos = $2 }
+{ for (i = 1; i <= n; i++) if (line[i] == NR) at[i] = pos }
+END {
+  for (i = 1; i <= n; i++) {
+    f = name[i]
+    gsub(/__/, ".", f)
+    if (f ~ /^GOTOSH_RT_/) continue
+    if (f !~ /\./) f = "main." f
+    printf "%s(...)\n\t%s\n", f, (at[i] != "" ? at[i] : FILENAME != "/dev/null" ? FILENAME ":" line[i] : "?")
+  }
+}' "$src"
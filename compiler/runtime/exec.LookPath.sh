@@ -0,0 +1 @@
+type -P -- "$1" || return 127
@@ -0,0 +1,5 @@
+local st
+GOTOSH_RET_0=$(GOTOSH_RT_exec__run "$1" "$2"; st=$?; printf x; exit "$st")
+st=$?
+GOTOSH_RET_0=${GOTOSH_RET_0%x}
+return "$st"
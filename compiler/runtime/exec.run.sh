@@ -0,0 +1,59 @@
+local -n args="$1__Args" env="$1__Env"
+local v dir in out err path tout= terr= so= se= st
+v=$1__Dir
+dir=${!v}
+v=$1__Stdin
+in=${!v}
+v=$1__Stdout
+out=${!v}
+v=$1__Stderr
+err=${!v}
+case $2 in
+  output) out=1 ;;
+  combined) out=1 err=1 ;;
+  pipe) in=0 out=1 ;;
+esac
+# a relative path is evaluated in the directory of the command
+path=$([ -z "$dir" ] || cd -- "$dir" 2>/dev/null || exit 2; type -P -- "${args[0]}" || exit 127) || return
+(
+  [ -z "$dir" ] || cd -- "$dir" || exit 2
+  case $in in
+    ''|*[!0-9]*) exec <"${in:-/dev/null}" ;;
+    *) exec <&"$in" ;;
+  esac
+  # the output for the writers of io.MultiWriter is kept in a file and copied to each of them when the command returns
+  case $out in
+    *' '*) tout=$(mktemp) && exec {so}>&1 >"$tout" || exit 1 ;;
+    ''|*[!0-9]*) exec >"${out:-/dev/null}" ;;
+    *) exec >&"$out" ;;
+  esac
+  case $err in
+    *' '*) terr=$(mktemp) && exec {se}>&2 2>"$terr" || exit 1 ;;
+    ''|*[!0-9]*) exec 2>"${err:-/dev/null}" ;;
+    *) exec 2>&"$err" ;;
+  esac
+  if [ -z "$tout$terr" ] && [ "${#env[@]}" -gt 0 ]; then
+    exec env -i "${env[@]}" "$path" "${args[@]:1}"
+  elif [ -z "$tout$terr" ]; then
+    exec -a "${args[0]}" "$path" "${args[@]:1}"
+  elif [ "${#env[@]}" -gt 0 ]; then
+    env -i "${env[@]}" "$path" "${args[@]:1}"
+  else
+    (exec -a "${args[0]}" "$path" "${args[@]:1}")
+  fi
+  st=$?
+  exec >&"${so:-1}" 2>&"${se:-2}"
+  if [ -n "$tout" ]; then
+    GOTOSH_RT_io__Copy "$out" 0 <"$tout" || st=1
+  fi
+  if [ -n "$terr" ]; then
+    GOTOSH_RT_io__Copy "$err" 0 <"$terr" || st=1
+  fi
+  rm -f -- $tout $terr
+  exit "$st"
+) <&0 &
+if [ "$2" = start ]; then
+  printf -v "$1__Process__Pid" '%s' "$!"
+  return 0
+fi
+wait "$!"
@@ -0,0 +1,5 @@
+case $1 in
+  /*) GOTOSH_RT_filepath__normalize "$1" ;;
+  *) GOTOSH_RT_filepath__normalize "${PWD:-$(pwd)}/$1" ;;
+esac
+printf '%s\n' "$GOTOSH_RET_0"
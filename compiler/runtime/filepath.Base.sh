@@ -0,0 +1,5 @@
+local p="$1"
+[ -n "$p" ] || { printf '.\n'; return 0; }
+p=${p%"${p##*[!/]}"}
+[ -n "$p" ] || { printf '/\n'; return 0; }
+printf '%s\n' "${p##*/}"
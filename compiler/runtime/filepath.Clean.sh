@@ -0,0 +1,2 @@
+GOTOSH_RT_filepath__normalize "$1"
+printf '%s\n' "$GOTOSH_RET_0"
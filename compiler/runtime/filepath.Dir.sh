@@ -0,0 +1,5 @@
+case $1 in
+  */*) GOTOSH_RT_filepath__normalize "${1%/*}/" ;;
+  *) GOTOSH_RET_0=. ;;
+esac
+printf '%s\n' "$GOTOSH_RET_0"
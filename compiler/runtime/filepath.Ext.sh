@@ -0,0 +1,5 @@
+local b="${1##*/}"
+case $b in
+  *.*) printf '.%s\n' "${b##*.}" ;;
+  *) printf '\n' ;;
+esac
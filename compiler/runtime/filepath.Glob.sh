@@ -0,0 +1,24 @@
+local p pat dotglob=-u nullglob=-u IFS= LC_ALL=C
+GOTOSH_RT_filepath__pattern "$1" || { GOTOSH_RET_0=(); return 1; }
+pat=$GOTOSH_RET_0
+GOTOSH_RET_0=()
+case $1 in
+  *[\*\?\[\\]*) ;;
+  *)
+    if [ -e "$1" ] || [ -L "$1" ]; then
+      GOTOSH_RET_0=("$1")
+    fi
+    return 0
+    ;;
+esac
+shopt -q dotglob && dotglob=-s
+shopt -q nullglob && nullglob=-s
+shopt -s dotglob nullglob
+for p in $pat; do
+  case ${p##*/} in
+    .|..) ;;
+    *) GOTOSH_RET_0+=("$p") ;;
+  esac
+done
+shopt "$dotglob" dotglob
+shopt "$nullglob" nullglob
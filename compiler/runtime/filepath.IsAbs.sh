@@ -0,0 +1,4 @@
+case $1 in
+  /*) printf '1\n' ;;
+  *) printf '0\n' ;;
+esac
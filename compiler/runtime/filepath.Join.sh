@@ -0,0 +1,9 @@
+local p= e
+for e in "$@"; do
+  [ -n "$e" ] && p=${p:+$p/}$e
+done
+if [ -n "$p" ]; then
+  GOTOSH_RT_filepath__normalize "$p"
+  p=$GOTOSH_RET_0
+fi
+printf '%s\n' "$p"
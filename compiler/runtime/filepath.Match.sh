@@ -0,0 +1,17 @@
+local pat name="$2" p s
+GOTOSH_RT_filepath__pattern "$1" || { printf '0\n'; return 1; }
+pat=$GOTOSH_RET_0
+p=${pat//[!\/]/}
+s=${name//[!\/]/}
+[ "${#p}" = "${#s}" ] || { printf '0\n'; return 0; }
+while :; do
+  case ${name%%/*} in
+    ${pat%%/*}) ;;
+    *) printf '0\n'; return 0 ;;
+  esac
+  case $pat in
+    */*) pat=${pat#*/}; name=${name#*/} ;;
+    *) break ;;
+  esac
+done
+printf '1\n'
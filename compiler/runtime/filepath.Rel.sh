@@ -0,0 +1,32 @@
+local base targ r= i=0 k=0 IFS=/
+local -a b=() t=()
+GOTOSH_RT_filepath__normalize "$1"
+base=$GOTOSH_RET_0
+GOTOSH_RT_filepath__normalize "$2"
+targ=$GOTOSH_RET_0
+if [ "$base" = "$targ" ]; then
+  printf '.\n'
+  return 0
+fi
+[ "$base" != . ] || base=
+[ "$targ" != . ] || targ=
+case $base:$targ in
+  /*:/*) base=${base#/}; targ=${targ#/} ;;
+  /*:*|*:/*) printf '\n'; return 1 ;;
+esac
+[ -z "$base" ] || read -r -a b <<< "$base"
+[ -z "$targ" ] || read -r -a t <<< "$targ"
+while [ "$k" -lt "${#b[@]}" ] && [ "$k" -lt "${#t[@]}" ] && [ "${b[k]}" = "${t[k]}" ]; do
+  k=$((k + 1))
+done
+if [ "${b[k]-}" = .. ]; then
+  printf '\n'
+  return 1
+fi
+i=$k
+while [ "$i" -lt "${#b[@]}" ]; do
+  r=${r:+$r/}..
+  i=$((i + 1))
+done
+[ "$k" -ge "${#t[@]}" ] || r=${r:+$r/}${t[*]:k}
+printf '%s\n' "$r"
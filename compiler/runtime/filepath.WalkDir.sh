@@ -0,0 +1 @@
+GOTOSH_RT_filepath__walktree "$1" "$2" entry
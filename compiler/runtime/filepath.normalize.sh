@@ -0,0 +1,27 @@
+local p="$1" seg rooted= n=0 IFS=/
+local -a out=()
+case $p in
+  /*) rooted=/ ;;
+esac
+while [ -n "$p" ]; do
+  seg=${p%%/*}
+  [ "$seg" = "$p" ] && p= || p=${p#*/}
+  case $seg in
+    ''|.) ;;
+    ..)
+      if [ "$n" -gt 0 ] && [ "${out[n-1]}" != .. ]; then
+        n=$((n - 1))
+        unset 'out[n]'
+      elif [ -z "$rooted" ]; then
+        out[n]=..
+        n=$((n + 1))
+      fi
+      ;;
+    *)
+      out[n]=$seg
+      n=$((n + 1))
+      ;;
+  esac
+done
+p=$rooted${out[*]}
+GOTOSH_RET_0=${p:-.}
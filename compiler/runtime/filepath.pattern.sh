@@ -0,0 +1,30 @@
+# Converts the pattern of Go to the shell, e.g. [^a] to [!a], or returns 1 (ErrBadPattern).
+local pat="$1" i=0 c out=
+while [ "$i" -lt "${#pat}" ]; do
+  c=${pat:i:1}
+  case $c in
+    \\)
+      i=$((i + 1))
+      [ "$i" -lt "${#pat}" ] || return 1
+      c=$c${pat:i:1}
+      ;;
+    \[)
+      i=$((i + 1))
+      c='['
+      [ "${pat:i:1}" != ^ ] || { c='[!'; i=$((i + 1)); }
+      [ "${pat:i:1}" != ] ] || return 1
+      # a leading ! is a literal in Go, but negates the class in the shell
+      [ "$c${pat:i:1}" != '[!' ] || { c='[\!'; i=$((i + 1)); }
+      while [ "$i" -lt "${#pat}" ] && [ "${pat:i:1}" != ] ]; do
+        [ "${pat:i:1}" != \\ ] || { c="$c\\"; i=$((i + 1)); }
+        c=$c${pat:i:1}
+        i=$((i + 1))
+      done
+      [ "$i" -lt "${#pat}" ] || return 1
+      c=$c]
+      ;;
+  esac
+  out=$out$c
+  i=$((i + 1))
+done
+GOTOSH_RET_0=$out
@@ -0,0 +1,44 @@
+local root="$1" fn="$2" p t skip= st=0 fd
+if [ ! -e "$root" ] && [ ! -L "$root" ]; then
+  if [ "$3" = info ]; then
+    "$fn" "$root" "" 0 0 0 2
+  else
+    "$fn" "$root" "" 2
+  fi
+  st=$?
+  [ "$st" != 254 ] && [ "$st" != 253 ] || st=0
+  return "$st"
+fi
+exec {fd}< <(find "$root" -print0 2>/dev/null | tr '/' '\001' | LC_ALL=C sort -z | tr '\001' '/')
+while IFS= read -r -d '' -u "$fd" p; do
+  if [ -n "$skip" ]; then
+    case $p in
+      "$skip"*) continue ;;
+    esac
+    skip=
+  fi
+  GOTOSH_RT_fs__modetype "$p"
+  t=$GOTOSH_RET_0
+  if [ "$3" = info ]; then
+    GOTOSH_RT_os__Lstat "$p"
+    "$fn" "$p" "$GOTOSH_RET_0__name" "$GOTOSH_RET_0__size" "$GOTOSH_RET_0__mode" "$GOTOSH_RET_0__modTime" 0
+  else
+    "$fn" "$p" "$t/${p%/}" 0
+  fi
+  st=$?
+  case $st in
+    0) ;;
+    254)
+      st=0
+      if [ "$t" = 2147483648 ]; then
+        skip=${p%/}/
+      else
+        skip=${p%/*}/
+      fi
+      ;;
+    253) st=0; break ;;
+    *) break ;;
+  esac
+done
+exec {fd}<&-
+return "$st"
@@ -0,0 +1,3 @@
+GOTOSH_RET_0=
+[ "$1" -ge 0 ] && [ "$1" -lt "$GOTOSH_FLAG_nargs" ] || return 0
+eval "GOTOSH_RET_0=\$GOTOSH_FLAG_arg_$1"
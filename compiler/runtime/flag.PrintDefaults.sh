@@ -0,0 +1,45 @@
+local i name kind def usage tname line rest nl tab
+nl=$(printf '\n.') tab=$(printf '\t')
+nl=${nl%.}
+# Flags are listed in the order of their names: "name<TAB>N" sorts like the name alone.
+i=0
+while [ "$i" -lt "$GOTOSH_FLAG_n" ]; do
+  eval "printf '%s\t%s\n' \"\$GOTOSH_FLAG_name_$i\" $i"
+  i=$(( i + 1 ))
+done | LC_ALL=C sort | while IFS= read -r line; do
+  i=${line##*"$tab"}
+  eval "name=\$GOTOSH_FLAG_name_$i kind=\$GOTOSH_FLAG_kind_$i def=\$GOTOSH_FLAG_def_$i usage=\$GOTOSH_FLAG_usage_$i"
+  case $kind in
+    b) tname= ;;
+    d) tname=duration ;;
+    i) tname=int ;;
+    *) tname=string ;;
+  esac
+  # A back-quoted word in the usage names the flag's argument.
+  case $usage in
+    *'`'*'`'*)
+      rest=${usage#*'`'}
+      tname=${rest%%'`'*}
+      usage=${usage%%'`'*}$tname${rest#*'`'}
+      ;;
+  esac
+  line="  -$name"
+  [ -z "$tname" ] || line="$line $tname"
+  if [ "${#line}" -le 4 ]; then
+    line=$line$tab
+  else
+    line=$line$nl"    "$tab
+  fi
+  while :; do
+    case $usage in
+      *"$nl"*) line=$line${usage%%"$nl"*}$nl"    "$tab usage=${usage#*"$nl"} ;;
+      *) line=$line$usage; break ;;
+    esac
+  done
+  case $kind:$def in
+    b:false | i:0 | s: | d:0s) ;;
+    s:*) line="$line (default $(GOTOSH_RT_strconv__Quote "$def"))" ;;
+    *) line="$line (default $def)" ;;
+  esac
+  printf '%s\n' "$line" >&"$GOTOSH_FLAG_out"
+done
@@ -0,0 +1,11 @@
+# Registers a flag as GOTOSH_FLAG_{name,kind,var,def,usage}_N and returns the variable name.
+# The variable is set by name, so the locals are prefixed not to hide it.
+local GOTOSH_flag_i=$GOTOSH_FLAG_n GOTOSH_flag_def=$3 GOTOSH_flag_var=${5:-GOTOSH_FLAG_v$GOTOSH_FLAG_n}
+eval "$GOTOSH_flag_var=\$GOTOSH_flag_def"
+case $1 in
+  b) [ "$GOTOSH_flag_def" -ne 0 ] && GOTOSH_flag_def=true || GOTOSH_flag_def=false ;;
+  d) GOTOSH_flag_def=$(GOTOSH_RT_time__Duration__String "$GOTOSH_flag_def") ;;
+esac
+eval "GOTOSH_FLAG_name_$GOTOSH_flag_i=\$2 GOTOSH_FLAG_kind_$GOTOSH_flag_i=\$1 GOTOSH_FLAG_var_$GOTOSH_flag_i=\$GOTOSH_flag_var GOTOSH_FLAG_def_$GOTOSH_flag_i=\$GOTOSH_flag_def GOTOSH_FLAG_usage_$GOTOSH_flag_i=\$4"
+GOTOSH_FLAG_n=$(( GOTOSH_flag_i + 1 ))
+GOTOSH_RET_0=$GOTOSH_flag_var
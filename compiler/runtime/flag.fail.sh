@@ -0,0 +1,3 @@
+printf '%s\n' "$1" >&"$GOTOSH_FLAG_out"
+"$flag__Usage"
+exit 2
@@ -0,0 +1,72 @@
+# The locals are prefixed not to hide the variables of the flags, which are set by name.
+local GOTOSH_flag_a GOTOSH_flag_name GOTOSH_flag_value GOTOSH_flag_has GOTOSH_flag_i GOTOSH_flag_kind GOTOSH_flag_var GOTOSH_flag_v
+while [ $# -gt 0 ]; do
+  GOTOSH_flag_a=$1
+  case $GOTOSH_flag_a in
+    --) shift; break ;;
+    -?*) ;;
+    *) break ;;
+  esac
+  GOTOSH_flag_name=${GOTOSH_flag_a#-}
+  case $GOTOSH_flag_name in -?*) GOTOSH_flag_name=${GOTOSH_flag_name#-} ;; esac
+  case $GOTOSH_flag_name in -* | =*) GOTOSH_RT_flag__fail "bad flag syntax: $GOTOSH_flag_a" ;; esac
+  shift
+  GOTOSH_flag_has=
+  case $GOTOSH_flag_name in
+    *=*) GOTOSH_flag_value=${GOTOSH_flag_name#*=} GOTOSH_flag_name=${GOTOSH_flag_name%%=*} GOTOSH_flag_has=1 ;;
+  esac
+  GOTOSH_flag_kind=
+  GOTOSH_flag_i=0
+  while [ "$GOTOSH_flag_i" -lt "$GOTOSH_FLAG_n" ]; do
+    eval "GOTOSH_flag_v=\$GOTOSH_FLAG_name_$GOTOSH_flag_i"
+    if [ "$GOTOSH_flag_v" = "$GOTOSH_flag_name" ]; then
+      eval "GOTOSH_flag_kind=\$GOTOSH_FLAG_kind_$GOTOSH_flag_i GOTOSH_flag_var=\$GOTOSH_FLAG_var_$GOTOSH_flag_i"
+      break
+    fi
+    GOTOSH_flag_i=$(( GOTOSH_flag_i + 1 ))
+  done
+  if [ -z "$GOTOSH_flag_kind" ]; then
+    if [ "$GOTOSH_flag_name" = help ] || [ "$GOTOSH_flag_name" = h ]; then
+      "$flag__Usage"
+      exit 0
+    fi
+    GOTOSH_RT_flag__fail "flag provided but not defined: -$GOTOSH_flag_name"
+  fi
+  if [ "$GOTOSH_flag_kind" = b ]; then
+    [ -n "$GOTOSH_flag_has" ] || GOTOSH_flag_value=true
+    case $GOTOSH_flag_value in
+      1 | t | T | TRUE | true | True) GOTOSH_flag_v=1 ;;
+      0 | f | F | FALSE | false | False) GOTOSH_flag_v=0 ;;
+      *) GOTOSH_RT_flag__fail "invalid boolean value $(GOTOSH_RT_strconv__Quote "$GOTOSH_flag_value") for -$GOTOSH_flag_name: parse error" ;;
+    esac
+  else
+    if [ -z "$GOTOSH_flag_has" ]; then
+      [ $# -gt 0 ] || GOTOSH_RT_flag__fail "flag needs an argument: -$GOTOSH_flag_name"
+      GOTOSH_flag_value=$1
+      shift
+    fi
+    case $GOTOSH_flag_kind in
+      i)
+        GOTOSH_flag_v=$(GOTOSH_RT_strconv__ParseInt "$GOTOSH_flag_value" 0 64)
+        case $? in
+          1) GOTOSH_RT_flag__fail "invalid value $(GOTOSH_RT_strconv__Quote "$GOTOSH_flag_value") for flag -$GOTOSH_flag_name: parse error" ;;
+          2) GOTOSH_RT_flag__fail "invalid value $(GOTOSH_RT_strconv__Quote "$GOTOSH_flag_value") for flag -$GOTOSH_flag_name: value out of range" ;;
+        esac
+        ;;
+      d)
+        GOTOSH_flag_v=$(GOTOSH_RT_time__ParseDuration "$GOTOSH_flag_value") ||
+          GOTOSH_RT_flag__fail "invalid value $(GOTOSH_RT_strconv__Quote "$GOTOSH_flag_value") for flag -$GOTOSH_flag_name: parse error"
+        ;;
+      *) GOTOSH_flag_v=$GOTOSH_flag_value ;;
+    esac
+  fi
+  eval "$GOTOSH_flag_var=\$GOTOSH_flag_v"
+done
+# The rest of the arguments are GOTOSH_FLAG_arg_N, and also a slice for flag.Args() on bash.
+GOTOSH_FLAG_nargs=$#
+GOTOSH_flag_i=0
+for GOTOSH_flag_a; do
+  eval "GOTOSH_FLAG_arg_$GOTOSH_flag_i=\$GOTOSH_flag_a"
+  GOTOSH_flag_i=$(( GOTOSH_flag_i + 1 ))
+done
+[ -z "${BASH_VERSION-}" ] || eval 'GOTOSH_FLAG_args=("$@")'
@@ -0,0 +1,2 @@
+printf 'Usage of %s:\n' "$0" >&"$GOTOSH_FLAG_out"
+GOTOSH_RT_flag__PrintDefaults
@@ -0,0 +1,41 @@
+awk 'function s(x) { return sin(x) }
+function c(x) { return cos(x) }
+function a(x) { return atan2(x, 1) }
+function l(x) { return log(x) }
+function e(x) { return exp(x) }
+function go_trunc(x) { return int(x) }
+function go_floor(x,   y) { y = int(x); return y > x ? y - 1 : y }
+function go_ceil(x,   y) { y = int(x); return y < x ? y + 1 : y }
+function go_round(x) { return x < 0 ? -go_floor(-x + .5) : go_floor(x + .5) }
+function go_abs(x) { return x < 0 ? -x : x }
+function go_mod(x, y) { return x % y }
+# some awks compare NaN as equal to any number
+function go_isnan(x) { return sprintf("%g", x) ~ /nan/ }
+function go_max(x, y) { return sprintf("%g %g", x, y) ~ /(^| )[+]?inf/ ? Inf : go_isnan(x) ? x : go_isnan(y) ? y : x > y ? x : y }
+function go_min(x, y) { return sprintf("%g %g", x, y) ~ /-inf/ ? -Inf : go_isnan(x) ? x : go_isnan(y) ? y : x < y ? x : y }
+function go_pow(x, y) { return x ^ y }
+function go_log2(x,   r) { r = go_round(log(x) / log(2)); return 2 ^ r == x ? r : log(x) / log(2) }
+function go_log10(x,   r) { r = go_round(log(x) / log(10)); return 10 ^ r == x ? r : log(x) / log(10) }
+function go_cbrt(x,   y) {
+  if (x == 0) return 0
+  y = go_abs(x) ^ (1 / 3)
+  y -= (y * y * y - go_abs(x)) / (3 * y * y)
+  return x < 0 ? -y : y
+}
+function go_tan(x) { return sin(x) / cos(x) }
+function go_asin(x) { return atan2(x, sqrt(1 - x * x)) }
+function go_acos(x) { return atan2(sqrt(1 - x * x), x) }
+function go_atan2(y, x) { return atan2(y, x) }
+function go_sinh(x) { return (exp(x) - exp(-x)) / 2 }
+function go_cosh(x) { return (exp(x) + exp(-x)) / 2 }
+function go_tanh(x) { return (exp(x) - exp(-x)) / (exp(x) + exp(-x)) }
+# shortest representation which reads back to the same value
+function go_str(x,   p, v) {
+  v = sprintf("%g", x)
+  if (v ~ /nan/) return "NaN"
+  if (v ~ /inf/) return v ~ /-/ ? "-Inf" : "+Inf"
+  for (p = 1; p < 17; p++) if ((v = sprintf("%." p "g", x)) + 0 == x) return v
+  return sprintf("%.17g", x)
+}
+# +Inf, -Inf and NaN are stored as in Go. awk reads them as these variables.
+BEGIN { Inf = "+inf" + 0; NaN = "+nan" + 0; print go_str('"$1"') }'
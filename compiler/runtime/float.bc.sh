@@ -0,0 +1,18 @@
+local line=
+GOTOSH_RET_0=
+# bc can't calculate with Inf and NaN. The expression is given if the program may use them.
+case ${2-} in
+  *Inf*|*NaN*) GOTOSH_RET_0=$(GOTOSH_RT_float__awk "$2"); return ;;
+esac
+if [ -z "${GOTOSH_BC_IN-}" ]; then
+  GOTOSH_RET_0=$(printf '%s\n' "$1" | bc -l)
+  return
+fi
+# an empty line follows the result. long results are split into lines ending with \.
+printf '%s\n"\n"\n' "$1" >&"$GOTOSH_BC_IN"
+while IFS= read -r line <&"$GOTOSH_BC_OUT" && [ -n "$line" ]; do
+  case $GOTOSH_RET_0 in
+    *\\) GOTOSH_RET_0=${GOTOSH_RET_0%\\}$line ;;
+    *) GOTOSH_RET_0=$line ;;
+  esac
+done
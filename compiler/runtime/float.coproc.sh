@@ -0,0 +1,8 @@
+local d
+d=$(mktemp -d) && mkfifo "$d/in" "$d/out" || return 0
+# bc is started by a subshell not to be waited by wait.
+( bc -l <"$d/in" >"$d/out" & )
+GOTOSH_BC_IN=$(( GOTOSH_fd=${GOTOSH_fd:-2}+1 ))
+GOTOSH_BC_OUT=$(( GOTOSH_fd=GOTOSH_fd+1 ))
+eval "exec $GOTOSH_BC_IN>\"\$d/in\" $GOTOSH_BC_OUT<\"\$d/out\""
+rm -rf "$d"
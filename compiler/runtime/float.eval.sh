@@ -0,0 +1,11 @@
+# bc can't calculate with Inf and NaN.
+case $2 in
+  *Inf*|*NaN*) GOTOSH_RT_float__awk "$2" ;;
+  *)
+    if command -v bc >/dev/null 2>&1; then
+      printf '%s\n' "$1" | bc -l
+    else
+      GOTOSH_RT_float__awk "$2"
+    fi
+    ;;
+esac
@@ -0,0 +1,35 @@
+awk -v verb="$1" -v flags="$2" -v width="$3" -v prec="$4" -v x="$5" -v exact="${GOTOSH_FLOAT_PREC-}" '
+function shortest(x, e,   p, s, n) {
+  if (x == 0) return "0"
+  for (p = 0; p < 16; p++) if (sprintf("%." p "e", x) + 0 == x) break
+  s = sprintf("%." p e, x)
+  n = substr(s, index(s, e) + 1) + 0
+  if (n < -4 || n >= 6) return s
+  return sprintf("%." (p > n ? p - n : 0) "f", x)
+}
+BEGIN {
+  if (x ~ /^[-+]?Inf$|^NaN$/) {
+    s = x ~ /^-/ ? "-Inf" : x == "NaN" ? "NaN" : "+Inf"
+    while (length(s) < width + 0) s = index(flags, "-") ? s " " : " " s
+  } else if (verb ~ /[vgG]/ && (prec == "" || prec == "-1")) {
+    s = shortest(x + 0, verb == "G" ? "E" : "e")
+    # all digits calculated with shell.SetFloatPrecision
+    if (exact != "" && x ~ /^-?[0-9]*\.?[0-9]*$/) {
+      s = x
+      if (s ~ /\./) sub(/\.?0*$/, "", s)
+      sub(/^\./, "0.", s)
+      sub(/^-\./, "-0.", s)
+    }
+    if (verb != "v" && index(flags, "+") && s !~ /^-/) s = "+" s
+    while (length(s) < width + 0) s = index(flags, "-") ? s " " : " " s
+  } else if (prec == "-1") {
+    f = verb ~ /[eE]/ ? "e" : "f"
+    for (p = 0; p < 330; p++) if (sprintf("%." p f, x) + 0 == x + 0) break
+    s = sprintf("%." p (f == "e" ? verb : f), x)
+  } else {
+    if (verb == "F") verb = "f"
+    if (verb == "v") verb = "g"
+    s = sprintf("%" flags width prec verb, x)
+  }
+  printf "%s", s
+}'
@@ -0,0 +1,2 @@
+GOTOSH_RT_fmt__sprintf "$@"
+printf '%s' "$GOTOSH_RET_0"
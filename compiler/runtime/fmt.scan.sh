@@ -0,0 +1,17 @@
+local GOTOSH_n=0 GOTOSH_line=
+GOTOSH_fmt_in=${GOTOSH_fmt_stdin:-}
+while [ $# -gt 0 ]; do
+  while [ -z "${GOTOSH_fmt_in//[ $'\t\r\n']/}" ]; do
+    IFS= read -r GOTOSH_line || [ -n "$GOTOSH_line" ] || break 2
+    GOTOSH_fmt_in=$GOTOSH_line$'\n'
+  done
+  GOTOSH_RT_fmt__scanvalue v "$1" || break
+  eval "$2=\$GOTOSH_fmt_v"
+  GOTOSH_n=$(( GOTOSH_n + 1 ))
+  shift 2
+done
+# Like Go reading stdin, the newline after the last value is consumed, so a following Scanln reads the next line.
+[ -n "${GOTOSH_fmt_in//[ $'\t\r\n']/}" ] || GOTOSH_fmt_in=
+GOTOSH_fmt_stdin=$GOTOSH_fmt_in
+GOTOSH_RET_0=$GOTOSH_n
+[ $# -eq 0 ]
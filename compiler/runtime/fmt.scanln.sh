@@ -0,0 +1,7 @@
+local GOTOSH_line=${GOTOSH_fmt_stdin:-}
+GOTOSH_fmt_stdin=
+if [ -z "$GOTOSH_line" ] && ! IFS= read -r GOTOSH_line && [ -z "$GOTOSH_line" ]; then
+  GOTOSH_RET_0=0
+  return 1
+fi
+GOTOSH_RT_fmt__sscan "${GOTOSH_line%$'\n'}" "$@" && [ -z "${GOTOSH_fmt_in//[ $'\t\r']/}" ]
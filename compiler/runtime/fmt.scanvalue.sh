@@ -0,0 +1,66 @@
+local verb="$1" kind="$2" tok= c=
+GOTOSH_fmt_in=${GOTOSH_fmt_in#"${GOTOSH_fmt_in%%[! $'\t\r\n']*}"}
+case $verb:$kind in
+  q:*)
+    case $GOTOSH_fmt_in in
+      \`*\`*)
+        tok=${GOTOSH_fmt_in#?}
+        tok=${tok%%\`*}
+        GOTOSH_fmt_in=${GOTOSH_fmt_in#?"$tok"?}
+        ;;
+      \"*)
+        GOTOSH_fmt_in=${GOTOSH_fmt_in#?}
+        while :; do
+          [ -z "$GOTOSH_fmt_in" ] && return 1
+          c=${GOTOSH_fmt_in:0:1}
+          GOTOSH_fmt_in=${GOTOSH_fmt_in:1}
+          case $c in
+            \") break ;;
+            \\)
+              c=${GOTOSH_fmt_in:0:1}
+              GOTOSH_fmt_in=${GOTOSH_fmt_in:1}
+              case $c in
+                n) c=$'\n' ;;
+                t) c=$'\t' ;;
+                r) c=$'\r' ;;
+              esac
+              ;;
+          esac
+          tok=$tok$c
+        done
+        ;;
+      *) return 1 ;;
+    esac
+    ;;
+  *:i)
+    case $GOTOSH_fmt_in in
+      [-+]*) tok=${GOTOSH_fmt_in:0:1}; GOTOSH_fmt_in=${GOTOSH_fmt_in:1} ;;
+    esac
+    c=${GOTOSH_fmt_in%%[!0-9]*}
+    [ -z "$c" ] && return 1
+    GOTOSH_fmt_in=${GOTOSH_fmt_in#"$c"}
+    tok=$(( ${tok}10#$c ))
+    ;;
+  *:f)
+    tok=${GOTOSH_fmt_in%%[!0-9.eE+-]*}
+    case $tok in
+      *[0-9]*) ;;
+      *) return 1 ;;
+    esac
+    GOTOSH_fmt_in=${GOTOSH_fmt_in#"$tok"}
+    tok=${tok#+}
+    ;;
+  *)
+    tok=${GOTOSH_fmt_in%%[ $'\t\r\n']*}
+    [ -z "$tok" ] && return 1
+    GOTOSH_fmt_in=${GOTOSH_fmt_in#"$tok"}
+    if [ "$kind" = b ]; then
+      case $tok in
+        1|t|T|true|TRUE|True) tok=1 ;;
+        0|f|F|false|FALSE|False) tok=0 ;;
+        *) return 1 ;;
+      esac
+    fi
+    ;;
+esac
+GOTOSH_fmt_v=$tok
@@ -0,0 +1,40 @@
+local f="$1" out= c= flags= width= prec= verb= sep=
+shift
+while [ -n "$f" ]; do
+  c=${f%%\%*}
+  out=$out$c
+  [ "$c" = "$f" ] && break
+  f=${f#*%}
+  flags=${f%%[!-+ #0]*}
+  f=${f#"$flags"}
+  width=${f%%[!0-9]*}
+  f=${f#"$width"}
+  prec=
+  case $f in
+    .*) f=${f#.}; prec=${f%%[!0-9]*}; f=${f#"$prec"}; prec=.$prec ;;
+  esac
+  verb=${f:0:1}
+  f=${f:1}
+  if [ "$verb" = % ]; then
+    out=$out%
+  elif [ -z "$verb" ]; then
+    out="$out%!(NOVERB)"
+  elif [ $# -eq 0 ]; then
+    out="$out%!$verb(MISSING)"
+  else
+    GOTOSH_RT_fmt__value "$verb" "$flags" "$width" "$prec" "$@" || GOTOSH_fmt_v="%!$verb(BADKIND)" GOTOSH_fmt_n=1
+    out=$out$GOTOSH_fmt_v
+    shift "$GOTOSH_fmt_n"
+  fi
+done
+if [ $# -gt 0 ]; then
+  out="$out%!(EXTRA "
+  while [ $# -gt 0 ]; do
+    GOTOSH_RT_fmt__value v "" "" "" "$@" || GOTOSH_fmt_v='%!v(BADKIND)' GOTOSH_fmt_n=1
+    out="$out$sep$2=$GOTOSH_fmt_v"
+    sep=', '
+    shift "$GOTOSH_fmt_n"
+  done
+  out="$out)"
+fi
+GOTOSH_RET_0=$out
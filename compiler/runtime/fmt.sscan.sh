@@ -0,0 +1,11 @@
+local GOTOSH_n=0
+GOTOSH_fmt_in=$1
+shift
+while [ $# -gt 0 ]; do
+  GOTOSH_RT_fmt__scanvalue v "$1" || break
+  eval "$2=\$GOTOSH_fmt_v"
+  GOTOSH_n=$(( GOTOSH_n + 1 ))
+  shift 2
+done
+GOTOSH_RET_0=$GOTOSH_n
+[ $# -eq 0 ]
@@ -0,0 +1,34 @@
+local GOTOSH_n=0 GOTOSH_f="$2" GOTOSH_c=
+GOTOSH_fmt_in=$1
+shift 2
+while [ -n "$GOTOSH_f" ]; do
+  GOTOSH_c=${GOTOSH_f:0:1}
+  GOTOSH_f=${GOTOSH_f:1}
+  case $GOTOSH_c in
+    %)
+      GOTOSH_f=${GOTOSH_f#"${GOTOSH_f%%[!0-9]*}"}
+      GOTOSH_c=${GOTOSH_f:0:1}
+      GOTOSH_f=${GOTOSH_f:1}
+      if [ "$GOTOSH_c" = % ]; then
+        GOTOSH_fmt_in=${GOTOSH_fmt_in#"${GOTOSH_fmt_in%%[! $'\t']*}"}
+        [ "${GOTOSH_fmt_in:0:1}" = % ] || break
+        GOTOSH_fmt_in=${GOTOSH_fmt_in:1}
+        continue
+      fi
+      [ $# -eq 0 ] && break
+      GOTOSH_RT_fmt__scanvalue "$GOTOSH_c" "$1" || break
+      eval "$2=\$GOTOSH_fmt_v"
+      GOTOSH_n=$(( GOTOSH_n + 1 ))
+      shift 2
+      ;;
+    ' '|$'\t')
+      GOTOSH_fmt_in=${GOTOSH_fmt_in#"${GOTOSH_fmt_in%%[! $'\t']*}"}
+      ;;
+    *)
+      [ "${GOTOSH_fmt_in:0:1}" = "$GOTOSH_c" ] || break
+      GOTOSH_fmt_in=${GOTOSH_fmt_in:1}
+      ;;
+  esac
+done
+GOTOSH_RET_0=$GOTOSH_n
+[ $# -eq 0 ] && [ -z "$GOTOSH_f" ]
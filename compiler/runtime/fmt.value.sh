@@ -0,0 +1,201 @@
+local verb="$1" flags="$2" width="$3" prec="$4" kind="$5" typ="$6" v= out= sep=' ' n=0 i=0 j=0 used=3 name= best= key= seen= sign= digits=0123456789abcdef
+shift 6
+case $verb$kind in
+  T*)
+    GOTOSH_RT_fmt__value v "" "" "" "$kind" "$typ" "$@"
+    used=$GOTOSH_fmt_n
+    out=$typ
+    ;;
+  ?a?)
+    n=$1
+    shift
+    used=$(( n + 3 ))
+    case $flags$verb in
+      *\#v) out="$typ{"; sep=', ' ;;
+      *) out='[' ;;
+    esac
+    while [ "$i" -lt "$n" ]; do
+      GOTOSH_RT_fmt__value "$verb" "$flags" "$width" "$prec" "${kind#a}" "${typ#\[\]}" "$1"
+      if [ "$i" -gt 0 ]; then out=$out$sep; fi
+      out=$out$GOTOSH_fmt_v
+      shift
+      i=$(( i + 1 ))
+    done
+    case $flags$verb in
+      *\#v) out="$out}" ;;
+      *) out="$out]" ;;
+    esac
+    ;;
+  ?m??)
+    n=$1
+    shift
+    used=$(( n * 2 + 3 ))
+    name=${typ#map\[}
+    name=${name%%\]*}
+    case $flags$verb in
+      *\#v) out="$typ{"; sep=', ' ;;
+      *) out='map[' ;;
+    esac
+    while [ "$i" -lt "$n" ]; do
+      best=
+      j=1
+      while [ "$j" -le "$n" ]; do
+        case " $seen " in
+          *" $j "*) ;;
+          *)
+            eval "v=\${$j}"
+            if [ -z "$best" ]; then
+              best=$j key=$v
+            elif [ "${kind:1:1}" = i ] && [ "$v" -lt "$key" ]; then
+              best=$j key=$v
+            elif [ "${kind:1:1}" != i ] && [ "$v" \< "$key" ]; then
+              best=$j key=$v
+            fi
+            ;;
+        esac
+        j=$(( j + 1 ))
+      done
+      seen="$seen $best"
+      if [ "$i" -gt 0 ]; then out=$out$sep; fi
+      GOTOSH_RT_fmt__value "$verb" "$flags" "$width" "$prec" "${kind:1:1}" "$name" "$key"
+      out=$out$GOTOSH_fmt_v:
+      eval "v=\${$(( best + n ))}"
+      GOTOSH_RT_fmt__value "$verb" "$flags" "$width" "$prec" "${kind:2:1}" "${typ#*\]}" "$v"
+      out=$out$GOTOSH_fmt_v
+      i=$(( i + 1 ))
+    done
+    case $flags$verb in
+      *\#v) out="$out}" ;;
+      *) out="$out]" ;;
+    esac
+    ;;
+  ?t)
+    n=$1
+    shift
+    case $flags$verb in
+      *\#v) out="${typ#\*}{"; sep=', ' ;;
+      *) out='{' ;;
+    esac
+    case $typ in
+      \**) out="&$out" ;;
+    esac
+    while [ "$i" -lt "$n" ]; do
+      name=$1
+      shift
+      GOTOSH_RT_fmt__value "$verb" "$flags" "$width" "$prec" "$@"
+      shift "$GOTOSH_fmt_n"
+      used=$(( used + GOTOSH_fmt_n + 1 ))
+      if [ "$i" -gt 0 ]; then out=$out$sep; fi
+      case $flags$verb in
+        *[+\#]v) out=$out$name: ;;
+      esac
+      out=$out$GOTOSH_fmt_v
+      i=$(( i + 1 ))
+    done
+    out="$out}"
+    ;;
+  *)
+    v=$1
+    if [ "$verb" = v ]; then
+      case $flags$kind in
+        *\#*s) verb=q ;;
+      esac
+      flags=${flags//[+#]/}
+    fi
+    case $verb$kind in
+      ?x|[vs]s) out=$v ;;
+      [vt]b) [ "${v:-0}" -ne 0 ] && out=true || out=false ;;
+      [vs]e)
+        if [ "${v:-0}" -ne 0 ]; then
+          out="exit status $v"
+        elif [ "$verb" = v ]; then
+          out='<nil>'
+        else
+          out='%!s(<nil>)'
+        fi
+        ;;
+      [vd]i)
+        out=$v
+        case $flags in
+          *+*) [ "$v" -ge 0 ] && out=+$v ;;
+          *\ *) [ "$v" -ge 0 ] && out=" $v" ;;
+        esac
+        ;;
+      [vgGeEfF]f) out=$(GOTOSH_RT_fmt__float "$verb" "$flags" "$width" "$prec" "$v"); width= ;;
+      [xXob]i)
+        case $verb in
+          [xX]) n=16 ;;
+          o) n=8 ;;
+          b) n=2 ;;
+        esac
+        [ "$verb" = X ] && digits=0123456789ABCDEF
+        i=${v#-}
+        while :; do
+          out=${digits:$(( i % n )):1}$out
+          i=$(( i / n ))
+          [ "$i" -eq 0 ] && break
+        done
+        case $flags$verb in
+          *\#x) out=0x$out ;;
+          *\#X) out=0X$out ;;
+          *\#o) out=0$out ;;
+          *\#b) out=0b$out ;;
+        esac
+        [ "$v" -lt 0 ] && out=-$out
+        ;;
+      [xX]s)
+        out=$(printf '%s' "$v" | od -An -tx1 -v | tr -d ' \n')
+        [ "$verb" = X ] && out=$(printf '%s' "$out" | tr a-f A-F)
+        ;;
+      qs)
+        v=${v//\\/\\\\}
+        v=${v//\"/\\\"}
+        v=${v//$'\n'/\\n}
+        v=${v//$'\t'/\\t}
+        v=${v//$'\r'/\\r}
+        out=\"$v\"
+        ;;
+      [qc]i)
+        out=$(awk -v c="$v" 'BEGIN { printf "%c", c + 0 }')
+        [ "$verb" = q ] && out="'$out'"
+        ;;
+      Ui)
+        i=$v
+        while [ "$i" -gt 0 ] || [ "${#out}" -lt 4 ]; do
+          out=${digits:$(( i % 16 )):1}$out
+          i=$(( i / 16 ))
+        done
+        out=U+$(printf '%s' "$out" | tr a-f A-F)
+        ;;
+      v*)
+        # The operands can't be split into values: consume the kind alone so the caller still progresses.
+        echo "fmt: unknown value kind '$kind'" >&2
+        GOTOSH_fmt_v='%!v(BADKIND)' GOTOSH_fmt_n=1
+        return 1
+        ;;
+      *)
+        GOTOSH_RT_fmt__value v "" "" "" "$kind" "$typ" "$v"
+        out="%!$verb($typ=$GOTOSH_fmt_v)"
+        width=
+        ;;
+    esac
+    if [ -n "$prec" ] && [ "$kind" = s ] && [ "$verb" != q ]; then
+      out=${out:0:${prec#.}}
+    fi
+    if [ -n "$width" ]; then
+      case $flags:$kind in
+        *-*:*) while [ "${#out}" -lt "$width" ]; do out="$out "; done ;;
+        *0*:[if])
+          case $out in
+            [-+\ ]*) sign=${out:0:1}; out=${out:1} ;;
+          esac
+          while [ $(( ${#out} + ${#sign} )) -lt "$width" ]; do out="0$out"; done
+          out=$sign$out
+          ;;
+        *) while [ "${#out}" -lt "$width" ]; do out=" $out"; done ;;
+      esac
+    fi
+    ;;
+esac
+GOTOSH_fmt_v=$out
+GOTOSH_fmt_n=$used
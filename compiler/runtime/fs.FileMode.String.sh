@@ -0,0 +1,18 @@
+# Returns the letters of the type bits followed by the permission bits like Go, e.g. drwxr-xr-x.
+local m=$1 types='dalTLDpSugct?' rwx=rwxrwxrwx out= c i=31
+while [ -n "$types" ]; do
+  c=${types%"${types#?}"}
+  types=${types#?}
+  [ $(( m >> i & 1 )) -eq 0 ] || out=$out$c
+  i=$(( i - 1 ))
+done
+[ -n "$out" ] || out=-
+i=8
+while [ -n "$rwx" ]; do
+  c=${rwx%"${rwx#?}"}
+  rwx=${rwx#?}
+  [ $(( m >> i & 1 )) -ne 0 ] || c=-
+  out=$out$c
+  i=$(( i - 1 ))
+done
+printf '%s\n' "$out"
@@ -0,0 +1,15 @@
+if [ -L "$1" ]; then
+  GOTOSH_RET_0=134217728
+elif [ -d "$1" ]; then
+  GOTOSH_RET_0=2147483648
+elif [ -p "$1" ]; then
+  GOTOSH_RET_0=33554432
+elif [ -S "$1" ]; then
+  GOTOSH_RET_0=16777216
+elif [ -c "$1" ]; then
+  GOTOSH_RET_0=69206016
+elif [ -b "$1" ]; then
+  GOTOSH_RET_0=67108864
+else
+  GOTOSH_RET_0=0
+fi
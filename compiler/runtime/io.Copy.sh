@@ -0,0 +1,26 @@
+# The writers of io.MultiWriter are separated by spaces. The data is copied to each of them after reading all.
+local out fd st w tmp=
+GOTOSH_RET_0=0
+case $1 in
+  *' '*) tmp=$(mktemp) && exec {fd}>"$tmp" ;;
+  *[!0-9]*) exec {fd}>>"$1" ;;
+  *) exec {fd}>&"$1" ;;
+esac || return 9
+out=$(LC_ALL=C dd bs=65536 <&"$2" 2>&1 >&"$fd")
+st=$?
+exec {fd}>&-
+if [ -n "$tmp" ]; then
+  for w in $1; do
+    case $w in
+      *[!0-9]*) cat -- "$tmp" >>"$w" ;;
+      *) cat -- "$tmp" >&"$w" ;;
+    esac || st=9
+  done
+  rm -f -- "$tmp"
+fi
+out=${out##*$'\n'}
+case ${out%% *} in
+  ''|*[!0-9]*) ;;
+  *) GOTOSH_RET_0=${out%% *} ;;
+esac
+return "$st"
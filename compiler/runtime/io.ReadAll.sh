@@ -0,0 +1,8 @@
+GOTOSH_RET_0=
+{ : <&"$1"; } 2>/dev/null || return 9
+# read stops at a NUL byte, which a shell variable can't hold.
+if IFS= read -r -d '' GOTOSH_RET_0 <&"$1"; then
+  printf 'io.ReadAll: NUL bytes are not supported\n' >&2
+  return 22
+fi
+return 0
@@ -0,0 +1,9 @@
+local LC_ALL=C w
+GOTOSH_RET_0=0
+for w in $1; do
+  case $w in
+    *[!0-9]*) printf '%s' "$2" >>"$w" ;;
+    *) printf '%s' "$2" >&"$w" ;;
+  esac || return 9
+done
+GOTOSH_RET_0=${#2}
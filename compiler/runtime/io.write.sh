@@ -0,0 +1,17 @@
+# Runs the command $2... writing its output to the writer $1. The writers of io.MultiWriter are separated by
+# spaces, and the output is written to each of them in turn when the command returns.
+local w out st
+case $1 in
+  *' '*) ;;
+  ''|*[!0-9]*) "${@:2}" >"${1:-/dev/null}"; return ;;
+  *) "${@:2}" >&"$1"; return ;;
+esac
+out=$("${@:2}"; st=$?; printf x; exit "$st")
+st=$?
+for w in $1; do
+  case $w in
+    *[!0-9]*) printf '%s' "${out%x}" >>"$w" ;;
+    *) printf '%s' "${out%x}" >&"$w" ;;
+  esac || st=9
+done
+return "$st"
@@ -0,0 +1,4 @@
+local m
+[ -e "$1" ] || return 2
+printf -v m '%o' $(( $2 & 8#7777 ))
+chmod "$m" -- "$1" 2>/dev/null || return 1
@@ -0,0 +1,5 @@
+local n
+GOTOSH_RET_0=()
+for n in $(compgen -e); do
+  GOTOSH_RET_0+=("$n=${!n-}")
+done
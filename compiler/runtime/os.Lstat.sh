@@ -0,0 +1,25 @@
+local p="$1" info t=0
+GOTOSH_RET_0__name= GOTOSH_RET_0__size=0 GOTOSH_RET_0__mode=0 GOTOSH_RET_0__modTime=0
+if [ -z "${2-}" ] && [ -L "$p" ]; then
+  t=134217728
+elif [ ! -e "$p" ]; then
+  return 2
+elif [ -d "$p" ]; then
+  t=2147483648
+elif [ -p "$p" ]; then
+  t=33554432
+elif [ -S "$p" ]; then
+  t=16777216
+elif [ -c "$p" ]; then
+  t=69206016
+elif [ -b "$p" ]; then
+  t=67108864
+fi
+info=$(stat ${2-} -c '%s %a %Y' -- "$p" 2>/dev/null || stat ${2-} -f '%z %Lp %m' -- "$p" 2>/dev/null) || return 13
+set -- $info
+p=${p%/}
+GOTOSH_RET_0__name=${p##*/}
+GOTOSH_RET_0__name=${GOTOSH_RET_0__name:-/}
+GOTOSH_RET_0__size=$1
+GOTOSH_RET_0__mode=$(( t | 8#$2 & 8#777 ))
+GOTOSH_RET_0__modTime=$(( $3 * 1000000000 ))
@@ -0,0 +1,14 @@
+local p dotglob=-u nullglob=-u LC_ALL=C
+local -a entries=()
+[ -e "$1" ] || { GOTOSH_RET_0=(); return 2; }
+[ -d "$1" ] || { GOTOSH_RET_0=(); return 20; }
+shopt -q dotglob && dotglob=-s
+shopt -q nullglob && nullglob=-s
+shopt -s dotglob nullglob
+for p in "${1%/}"/*; do
+  GOTOSH_RT_fs__modetype "$p"
+  entries+=("$GOTOSH_RET_0/$p")
+done
+shopt "$dotglob" dotglob
+shopt "$nullglob" nullglob
+GOTOSH_RET_0=(${entries[@]+"${entries[@]}"})
@@ -0,0 +1,10 @@
+GOTOSH_RET_0=
+[ -e "$1" ] || return 2
+[ ! -d "$1" ] || return 21
+[ -r "$1" ] || return 13
+# read stops at a NUL byte, which a shell variable can't hold.
+if IFS= read -r -d '' GOTOSH_RET_0 < "$1"; then
+  printf 'os.ReadFile: %s: NUL bytes are not supported\n' "$1" >&2
+  return 22
+fi
+return 0
@@ -0,0 +1,5 @@
+if [ ! -L "$1" ]; then
+  [ -e "$1" ] && return 22
+  return 2
+fi
+readlink -- "$1"
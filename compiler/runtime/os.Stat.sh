@@ -0,0 +1 @@
+GOTOSH_RT_os__Lstat "$1" -L
@@ -0,0 +1,2 @@
+[ ! -e "$2" ] && [ ! -L "$2" ] || return 17
+ln -s -- "$1" "$2" 2>/dev/null || return 1
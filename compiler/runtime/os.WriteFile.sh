@@ -0,0 +1,11 @@
+local m
+[ ! -d "$1" ] || return 21
+case $1 in
+  */*) [ -d "${1%/*}/" ] || return 2 ;;
+esac
+if [ ! -e "$1" ]; then
+  : > "$1" 2>/dev/null || return 13
+  printf -v m '%o' $(( $3 & ~8#$(umask) & 8#7777 ))
+  chmod "$m" -- "$1" || return 1
+fi
+printf '%s' "$2" 2>/dev/null > "$1" || return 13
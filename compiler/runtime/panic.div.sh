@@ -0,0 +1,3 @@
+# Panics if the divisor $1 is zero (--checked).
+[ "$1" -eq 0 ] || return 0
+GOTOSH_RT_panic__raise "runtime error: integer divide by zero"
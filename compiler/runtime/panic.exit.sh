@@ -0,0 +1,10 @@
+# Prints the panic not recovered and exits with the status 2 like Go. A subshell exits to the parent printing it.
+local file=${TMPDIR:-/tmp}/gotosh_panic.$$
+if [ "$BASH_SUBSHELL" -gt 0 ]; then
+  printf 'GOTOSH_PANIC=%q GOTOSH_PANIC_TRACE=%q\n' "$GOTOSH_PANIC" "$GOTOSH_PANIC_TRACE" > "$file"
+  exit 2
+fi
+[ ! -f "$file" ] || rm -f "$file"
+printf 'panic: %s\n\ngoroutine 1 [running]:\n%s\n' "$GOTOSH_PANIC" "$GOTOSH_PANIC_TRACE" >&2
+unset GOTOSH_PANIC # not printed again by the EXIT trap
+exit 2
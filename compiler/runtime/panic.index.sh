@@ -0,0 +1,3 @@
+# Panics if the index $1 is out of range of the length $2 (--checked).
+[ "$1" -lt 0 ] || [ "$1" -ge "$2" ] || return 0
+GOTOSH_RT_panic__raise "runtime error: index out of range [$1] with length $2"
@@ -0,0 +1,3 @@
+# Panics if the pointer $1 is nil (--checked). A pointer is a name reference, which is not set for nil.
+[[ ! -R $1 ]] || return 0
+GOTOSH_RT_panic__raise "runtime error: invalid memory address or nil pointer dereference"
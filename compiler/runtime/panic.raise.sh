@@ -0,0 +1,16 @@
+# Starts panicking with the value $1. The caller returns with the status 2, then the callers return checking
+# the status, running their deferred calls until recover() is called (see panic.unwind).
+# In $(f), the panic is passed to the parent through a file at once since the ERR trap may not run there.
+local f
+GOTOSH_PANIC=$1
+GOTOSH_RET_0= # the recovered function returns an empty value
+GOTOSH_PANIC_DEPTH=$(( ${#FUNCNAME[@]} - 1 ))
+GOTOSH_PANIC_TRACE=$(GOTOSH_RT_debug__stack 2 "${BASH_LINENO[0]}")
+if [ "$BASH_SUBSHELL" -gt 0 ]; then
+  printf 'GOTOSH_PANIC=%q GOTOSH_PANIC_TRACE=%q\n' "$GOTOSH_PANIC" "$GOTOSH_PANIC_TRACE" > "${TMPDIR:-/tmp}/gotosh_panic.$$"
+fi
+for f in "${FUNCNAME[@]:1:${#FUNCNAME[@]}-2}"; do
+  [[ $f == GOTOSH_RT_* ]] || return 2
+done
+# called at the top level
+GOTOSH_RT_panic__exit
@@ -0,0 +1,9 @@
+# Stops panicking and sets the value to GOTOSH_RECOVERED, which is empty (nil) if not panicking.
+# A panic in $(f) is read from the file when the status of $(f) was not checked (see panic.unwind).
+local file=${TMPDIR:-/tmp}/gotosh_panic.$$
+if [ -f "$file" ]; then
+  [ -n "${GOTOSH_PANIC+x}" ] || . "$file"
+  rm -f "$file"
+fi
+GOTOSH_RECOVERED=${GOTOSH_PANIC-}
+unset GOTOSH_PANIC
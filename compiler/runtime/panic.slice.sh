@@ -0,0 +1,8 @@
+# Panics if the slice expression [$1:$2] is out of range of the length $3 (--checked).
+# $4 is "length" for strings and "capacity" for slices as the message of Go.
+local low=${1:-0} high=${2:-$3}
+if [ "$high" -lt 0 ] || [ "$high" -gt "$3" ]; then
+  GOTOSH_RT_panic__raise "runtime error: slice bounds out of range [:$high] with $4 $3"
+elif [ "$low" -lt 0 ] || [ "$low" -gt "$high" ]; then
+  GOTOSH_RT_panic__raise "runtime error: slice bounds out of range [$low:$high]"
+fi
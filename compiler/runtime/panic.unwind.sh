@@ -0,0 +1,15 @@
+# Called by the ERR trap. Returns 0 if the frame of the trap returns to continue panicking.
+# Deferred calls run in deeper frames, so their errors don't return.
+# The variables set in $(f) are lost, so a panic leaving a subshell is passed to the parent through a file.
+local depth=$(( ${#FUNCNAME[@]} - 1 )) file=${TMPDIR:-/tmp}/gotosh_panic.$$
+if [ -z "${GOTOSH_PANIC+x}" ]; then
+  [ -f "$file" ] || return 1
+  . "$file"
+  rm -f "$file"
+  GOTOSH_PANIC_DEPTH=$(( depth + 1 ))
+fi
+[ "$depth" -lt "$GOTOSH_PANIC_DEPTH" ] || return 1
+GOTOSH_PANIC_DEPTH=$depth
+# 1 is the top level, where FUNCNAME has "main" only in the function
+[ "$depth" -gt 1 ] || GOTOSH_RT_panic__exit
+[ "$BASH_SUBSHELL" -eq 0 ] || printf 'GOTOSH_PANIC=%q GOTOSH_PANIC_TRACE=%q\n' "$GOTOSH_PANIC" "$GOTOSH_PANIC_TRACE" > "$file"
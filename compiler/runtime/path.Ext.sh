@@ -0,0 +1 @@
+GOTOSH_RT_filepath__Ext "$@"
@@ -0,0 +1,7 @@
+local LC_ALL=$GOTOSH_REGEXP_LC idx i
+GOTOSH_RT_regexp__all "$1" "$2" "$3"
+idx=("${GOTOSH_RET_0[@]}")
+GOTOSH_RET_0=()
+for (( i = 0; i < ${#idx[@]}; i += 2 )); do
+  GOTOSH_RET_0+=("${2:idx[i]:idx[i+1]-idx[i]}")
+done
@@ -0,0 +1,9 @@
+local LC_ALL=$GOTOSH_REGEXP_LC ere=${1#*:}
+ere=${ere#*:}
+if [ -n "${BASH_VERSION-}" ]; then
+  [[ $2 =~ $ere ]] && printf '%s\n' "${BASH_REMATCH[0]}"
+else
+  GOTOSH_RT_regexp__line "$1"
+  printf '%s\n' "$2" | LC_ALL=$LC_ALL grep -Eo -- "$GOTOSH_RET_0" | head -n 1
+fi
+return 0
@@ -0,0 +1,11 @@
+local LC_ALL=$GOTOSH_REGEXP_LC groups=${1#*:} ere g
+ere=${groups#*:} groups=${groups%%:*}
+GOTOSH_RET_0=()
+[[ $2 =~ $ere ]] || return 0
+if [ -z "$groups" ]; then
+  GOTOSH_RET_0=("${BASH_REMATCH[@]}")
+  return 0
+fi
+for g in ${groups//,/ }; do
+  GOTOSH_RET_0+=("${BASH_REMATCH[${g%%=*}]}")
+done
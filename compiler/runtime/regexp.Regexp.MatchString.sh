@@ -0,0 +1,8 @@
+local LC_ALL=$GOTOSH_REGEXP_LC ere=${1#*:}
+ere=${ere#*:}
+if [ -n "${BASH_VERSION-}" ]; then
+  [[ $2 =~ $ere ]]
+else
+  GOTOSH_RT_regexp__line "$1"
+  printf '%s\n' "$2" | LC_ALL=$LC_ALL grep -Eq -- "$GOTOSH_RET_0"
+fi && printf '1\n' || printf '0\n'
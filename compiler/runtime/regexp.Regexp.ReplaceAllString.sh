@@ -0,0 +1,131 @@
+local LC_ALL=$GOTOSH_REGEXP_LC src=$2 groups=${1#*:} ere pos=0 last=0 out= t name g i c d nl
+ere=${groups#*:} groups=${groups%%:*}
+if [ -z "${BASH_VERSION-}" ]; then
+  # sed replaces line by line, and refers to the groups \1 to \9 of the ERE.
+  d=$(printf '\001') nl=$(printf '\n.')
+  nl=${nl%.}
+  t=$3
+  while [ -n "$t" ]; do
+    case $t in
+      '$$'*)
+        out=$out'$' t=${t#??}
+        continue
+        ;;
+      '${'*'}'*)
+        name=${t#??} && name=${name%%\}*}
+        case $name in
+          '' | *[!A-Za-z0-9_]*)
+            out=$out'$' t=${t#?}
+            continue
+            ;;
+        esac
+        t=${t#??"$name"?}
+        ;;
+      '$'[A-Za-z0-9_]*)
+        name=${t#?} && name=${name%%[!A-Za-z0-9_]*}
+        t=${t#?"$name"}
+        ;;
+      '$'*)
+        out=$out'$' t=${t#?}
+        continue
+        ;;
+      *)
+        name=${t%%'$'*} t=${t#"${t%%'$'*}"}
+        while [ -n "$name" ]; do
+          c=${name%"${name#?}"} name=${name#?}
+          case $c in
+            \\ | '&' | "$d") out=$out\\$c ;;
+            "$nl") out=$out\\$nl ;;
+            *) out=$out$c ;;
+          esac
+        done
+        continue
+        ;;
+    esac
+    # groups lists the ERE index of each Go group as "index" or "index=name".
+    g= i=0 c=${groups:+$groups,}
+    case $name in *[!0-9]*) ;; *) [ -n "$groups" ] || g=$name ;; esac
+    while [ -n "$c" ]; do
+      case $name in
+        *[!0-9]*) case ${c%%,*} in *=*) [ "${c%%,*}" != "${c%%=*}=$name" ] || g=${c%%=*} ;; esac ;;
+        *) [ "$i" -ne "$name" ] || g=${c%%[=,]*} ;;
+      esac
+      c=${c#*,} i=$(( i + 1 ))
+    done
+    case $g in
+      [0-9]) out=$out\\$g ;;
+    esac
+  done
+  GOTOSH_RT_regexp__line "$1"
+  printf '%s\n' "$src" | LC_ALL=$LC_ALL sed -E "s$d$GOTOSH_RET_0$d$out${d}g"
+  return 0
+fi
+while [ "$pos" -le "${#src}" ] && GOTOSH_RT_regexp__find "$1" "$src" "$pos"; do
+  out=$out${src:last:GOTOSH_RET_0-last}
+  # Don't replace an empty match right after the previous match.
+  if [ "$GOTOSH_RET_1" -gt "$last" ] || [ "$GOTOSH_RET_0" -eq 0 ]; then
+    t=$3
+    while [ -n "$t" ]; do
+      case $t in
+        '$$'*)
+          out=$out'$' t=${t#??}
+          continue
+          ;;
+        '${'*'}'*)
+          name=${t#??} && name=${name%%\}*}
+          case $name in
+            '' | *[!A-Za-z0-9_]*)
+              out=$out'$' t=${t#?}
+              continue
+              ;;
+          esac
+          t=${t#??"$name"?}
+          ;;
+        '$'[A-Za-z0-9_]*)
+          name=${t#?} && name=${name%%[!A-Za-z0-9_]*}
+          t=${t#?"$name"}
+          ;;
+        '$'*)
+          out=$out'$' t=${t#?}
+          continue
+          ;;
+        *)
+          name=${t%%'$'*}
+          out=$out$name t=${t#"$name"}
+          continue
+          ;;
+      esac
+      case $name in
+        *[!0-9]*)
+          for g in ${groups//,/ }; do
+            if [ "${g#*=}" = "$name" ] && [ "$g" != "$name" ]; then
+              out=$out${BASH_REMATCH[${g%%=*}]}
+              break
+            fi
+          done
+          ;;
+        *)
+          if [ -z "$groups" ]; then
+            out=$out${BASH_REMATCH[10#$name]-}
+          else
+            i=0
+            for g in ${groups//,/ }; do
+              if [ "$i" -eq $(( 10#$name )) ]; then
+                out=$out${BASH_REMATCH[${g%%=*}]}
+                break
+              fi
+              i=$(( i + 1 ))
+            done
+          fi
+          ;;
+      esac
+    done
+  fi
+  last=$GOTOSH_RET_1
+  if [ "$GOTOSH_RET_1" -gt "$pos" ]; then
+    pos=$GOTOSH_RET_1
+  else
+    pos=$(( pos + 1 ))
+  fi
+done
+printf '%s\n' "$out${src:last}"
@@ -0,0 +1,19 @@
+local LC_ALL=$GOTOSH_REGEXP_LC s=$2 n=$3 beg=0 end=0 i idx out=()
+GOTOSH_RET_0=()
+[ "$n" -ne 0 ] || return 0
+if [ -z "$s" ]; then
+  GOTOSH_RET_0=("")
+  return 0
+fi
+GOTOSH_RT_regexp__all "$1" "$s" "$n"
+idx=("${GOTOSH_RET_0[@]}")
+for (( i = 0; i < ${#idx[@]}; i += 2 )); do
+  if [ "$n" -gt 0 ] && [ "${#out[@]}" -eq $(( n - 1 )) ]; then
+    break
+  fi
+  end=${idx[i]}
+  [ "${idx[i+1]}" -eq 0 ] || out+=("${s:beg:end-beg}")
+  beg=${idx[i+1]}
+done
+[ "$end" -eq "${#s}" ] || out+=("${s:beg}")
+GOTOSH_RET_0=("${out[@]}")
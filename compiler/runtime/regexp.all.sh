@@ -0,0 +1,16 @@
+# Returns the start and end offsets of up to $3 matches like FindAllStringIndex. Empty matches right after a match are ignored.
+local n=$3 pos=0 prev=-1 idx=()
+while [ "$n" -lt 0 ] || [ $(( ${#idx[@]} / 2 )) -lt "$n" ]; do
+  [ "$pos" -le "${#2}" ] && GOTOSH_RT_regexp__find "$1" "$2" "$pos" || break
+  if [ "$GOTOSH_RET_1" -eq "$pos" ]; then
+    pos=$(( pos + 1 ))
+    if [ "$GOTOSH_RET_0" -eq "$prev" ]; then
+      continue
+    fi
+  else
+    pos=$GOTOSH_RET_1
+  fi
+  prev=$GOTOSH_RET_1
+  idx+=("$GOTOSH_RET_0" "$GOTOSH_RET_1")
+done
+GOTOSH_RET_0=("${idx[@]}")
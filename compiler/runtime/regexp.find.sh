@@ -0,0 +1,16 @@
+# Finds the leftmost match in $2 from the offset $3. The offsets of the match are set to GOTOSH_RET_0 and GOTOSH_RET_1, and the groups to BASH_REMATCH.
+# Without anchors, the text matched by an ERE matches at any position, so the first occurrence of the text is the match.
+local flags=${1%%:*} ere=${1#*:} rest=${2:$3} pre
+ere=${ere#*:}
+case $flags in
+  *^*) [ "$3" -eq 0 ] || return 1 ;;
+esac
+[[ $rest =~ $ere ]] || return 1
+case $flags in
+  *'$'*) GOTOSH_RET_0=$(( $3 + ${#rest} - ${#BASH_REMATCH[0]} )) ;;
+  *)
+    pre=${rest%%"${BASH_REMATCH[0]}"*}
+    GOTOSH_RET_0=$(( $3 + ${#pre} ))
+    ;;
+esac
+GOTOSH_RET_1=$(( GOTOSH_RET_0 + ${#BASH_REMATCH[0]} ))
@@ -0,0 +1,14 @@
+# Returns the ERE of $1 for grep and sed, which match line by line: [^\n] is any character,
+# and the other newlines are removed since a line has none.
+local ere=${1#*:} nl
+ere=${ere#*:}
+nl=$(printf '\n.')
+nl=${nl%.}
+while :; do
+  case $ere in
+    *"[^$nl]"*) ere=${ere%%"[^$nl]"*}.${ere#*"[^$nl]"} ;;
+    *"$nl"*) ere=${ere%%"$nl"*}${ere#*"$nl"} ;;
+    *) break ;;
+  esac
+done
+GOTOSH_RET_0=$ere
@@ -0,0 +1,9 @@
+# Prints the locale used for regexp: '.' and bracket expressions match a character like RE2 only in a UTF-8 locale.
+local l=${LC_ALL:-${LC_CTYPE:-${LANG-}}}
+case $l in
+  *.[Uu][Tt][Ff]-8 | *.[Uu][Tt][Ff]8) printf '%s\n' "${LC_ALL-}" ;;
+  *)
+    l=$(locale -a 2>/dev/null | grep -i -E '\.utf-?8$')
+    printf '%s\n' "$l" | grep -i -m 1 -E '^c\.' || printf '%s\n' "$l" | grep -i -m 1 -E '^en_us\.' || printf '%s\n' "${l:-${LC_ALL-}}" | head -n 1
+    ;;
+esac
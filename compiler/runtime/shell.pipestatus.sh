@@ -0,0 +1,9 @@
+local i=0 st
+GOTOSH_RET_0=()
+while [ "$i" -lt "$2" ]; do
+  st=
+  read -r st <"$1/$i" 2>/dev/null
+  GOTOSH_RET_0+=("${st:-1}")
+  i=$(( i + 1 ))
+done
+rm -rf -- "$1"
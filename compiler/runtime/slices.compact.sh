@@ -0,0 +1,8 @@
+local e
+GOTOSH_RET_0=()
+[ $# -gt 0 ] || return 0
+GOTOSH_RET_0=("$1")
+shift
+for e; do
+  [ "$e" = "${GOTOSH_RET_0[${#GOTOSH_RET_0[@]}-1]}" ] || GOTOSH_RET_0+=("$e")
+done
@@ -0,0 +1,21 @@
+# Returns the maximum (max) or the minimum (min) of numbers (i, f) or strings (s) in byte order.
+# The call is hoisted out of $(...) (GOTOSH_RET_0), so the panic on an empty list exits the script.
+local LC_ALL=C op=$1 kind=$2 m e
+shift 2
+if [ $# -eq 0 ]; then
+  printf 'panic: slices.%s: empty list\n' "$( [ "$op" = max ] && echo Max || echo Min )" >&2
+  exit 2
+fi
+m=$1
+shift
+for e; do
+  case $kind:$op in
+    i:max) [ "$e" -le "$m" ] || m=$e ;;
+    i:min) [ "$e" -ge "$m" ] || m=$e ;;
+    f:max) awk -v a="$e" -v b="$m" 'BEGIN { exit !(a + 0 > b + 0) }' && m=$e ;;
+    f:min) awk -v a="$e" -v b="$m" 'BEGIN { exit !(a + 0 < b + 0) }' && m=$e ;;
+    s:max) [[ $e > $m ]] && m=$e ;;
+    s:min) [[ $e < $m ]] && m=$e ;;
+  esac
+done
+GOTOSH_RET_0=$m
@@ -0,0 +1,10 @@
+local v=$1 e i=0
+shift
+for e; do
+  if [ "$e" = "$v" ]; then
+    printf '%d\n' "$i"
+    return 0
+  fi
+  i=$(( i + 1 ))
+done
+printf '%d\n' -1
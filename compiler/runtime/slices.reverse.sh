@@ -0,0 +1,5 @@
+local i
+GOTOSH_RET_0=()
+for (( i = $#; i > 0; i-- )); do
+  GOTOSH_RET_0+=("${!i}")
+done
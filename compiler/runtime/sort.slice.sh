@@ -0,0 +1,20 @@
+# Binary insertion sort of the array named $1. less is called with indices into the array as it is being sorted.
+# The sort is stable, so equal elements may be ordered differently from Go's sort.Slice for more than 12 elements.
+local LC_ALL=C GOTOSH_sort_i GOTOSH_sort_j GOTOSH_sort_lo GOTOSH_sort_hi GOTOSH_sort_v
+typeset -n GOTOSH_sort_a=$1
+for (( GOTOSH_sort_i = 1; GOTOSH_sort_i < ${#GOTOSH_sort_a[@]}; GOTOSH_sort_i++ )); do
+  GOTOSH_sort_lo=0 GOTOSH_sort_hi=$GOTOSH_sort_i
+  while [ "$GOTOSH_sort_lo" -lt "$GOTOSH_sort_hi" ]; do
+    GOTOSH_sort_j=$(( (GOTOSH_sort_lo + GOTOSH_sort_hi) / 2 ))
+    if [ "$("$2" "$GOTOSH_sort_i" "$GOTOSH_sort_j")" -ne 0 ]; then
+      GOTOSH_sort_hi=$GOTOSH_sort_j
+    else
+      GOTOSH_sort_lo=$(( GOTOSH_sort_j + 1 ))
+    fi
+  done
+  GOTOSH_sort_v=${GOTOSH_sort_a[GOTOSH_sort_i]}
+  for (( GOTOSH_sort_j = GOTOSH_sort_i; GOTOSH_sort_j > GOTOSH_sort_lo; GOTOSH_sort_j-- )); do
+    GOTOSH_sort_a[GOTOSH_sort_j]=${GOTOSH_sort_a[GOTOSH_sort_j-1]}
+  done
+  GOTOSH_sort_a[GOTOSH_sort_lo]=$GOTOSH_sort_v
+done
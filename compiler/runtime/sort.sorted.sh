@@ -0,0 +1,10 @@
+# Sorts numbers (i, f) or strings (s) in byte order, like Go.
+local kind=$1
+shift
+GOTOSH_RET_0=()
+[ $# -gt 0 ] || return 0
+case $kind in
+  i) mapfile -t GOTOSH_RET_0 < <(printf '%s\n' "$@" | LC_ALL=C sort -n) ;;
+  f) mapfile -t GOTOSH_RET_0 < <(printf '%s\n' "$@" | LC_ALL=C sort -g) ;;
+  *) mapfile -d '' -t GOTOSH_RET_0 < <(printf '%s\0' "$@" | LC_ALL=C sort -z) ;;
+esac
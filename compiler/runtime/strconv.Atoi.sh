@@ -0,0 +1 @@
+GOTOSH_RT_strconv__ParseInt "$1" 10 0
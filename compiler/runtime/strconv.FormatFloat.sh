@@ -0,0 +1,6 @@
+if [ "$3" -lt 0 ]; then
+  GOTOSH_RT_fmt__float "$2" "" "" -1 "$1"
+else
+  GOTOSH_RT_fmt__float "$2" "" "" ".$3" "$1"
+fi
+printf '\n'
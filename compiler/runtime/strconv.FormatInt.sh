@@ -0,0 +1,9 @@
+local v="$1" base="$2" r= d digits=0123456789abcdefghijklmnopqrstuvwxyz
+while :; do
+  d=$(( v % base ))
+  r=${digits:$(( d < 0 ? -d : d )):1}$r
+  v=$(( v / base ))
+  [ "$v" -eq 0 ] && break
+done
+[ "$1" -lt 0 ] && r=-$r
+printf '%s\n' "$r"
@@ -0,0 +1,5 @@
+case "$1" in
+  1|t|T|true|TRUE|True) printf '1\n' ;;
+  0|f|F|false|FALSE|False) printf '0\n' ;;
+  *) printf '0\n'; return 1 ;;
+esac
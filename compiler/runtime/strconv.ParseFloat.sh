@@ -0,0 +1,42 @@
+local s="$1" sign= m e=0 ip fp= n
+case $s in
+  [-+]*) [ "${s:0:1}" = - ] && sign=-; s=${s:1} ;;
+esac
+case ${s,,} in
+  inf|infinity) printf '%sInf\n' "${sign:-+}"; return 0 ;;
+  nan) printf 'NaN\n'; return 0 ;;
+esac
+m=${s%%[eE]*}
+if [ "$m" != "$s" ]; then
+  e=${s#*[eE]}
+  case ${e#[-+]} in
+    ''|*[!0-9]*) printf '0\n'; return 1 ;;
+  esac
+  n=${e#[-+]}
+  [ "${#n}" -le 3 ] || n=400
+  e=$(( ${e%"${e#[-+]}"}10#$n ))
+fi
+case $m in
+  ''|.|*[!0-9.]*|*.*.*) printf '0\n'; return 1 ;;
+esac
+ip=${m%%.*}
+[ "$ip" = "$m" ] || fp=${m#*.}
+while [ "$e" -gt 0 ]; do
+  ip=$ip${fp:0:1}
+  [ -n "$fp" ] && fp=${fp:1} || ip=${ip}0
+  e=$((e - 1))
+done
+while [ "$e" -lt 0 ]; do
+  fp=${ip: -1}$fp
+  [ -n "$ip" ] && ip=${ip%?} || fp=0$fp
+  e=$((e + 1))
+done
+ip=${ip#"${ip%%[!0]*}"}
+fp=${fp%"${fp##*[!0]}"}
+n=309
+[ "${2:-64}" -eq 32 ] && n=39
+if [ "${#ip}" -gt "$n" ]; then
+  printf '%sInf\n' "${sign:-+}"
+  return 2
+fi
+printf '%s%s%s\n' "$sign" "${ip:-0}" "${fp:+.$fp}"
@@ -0,0 +1,50 @@
+local s="$1" base="$2" bits="${3:-0}" neg= v=0 d lim c digits=0123456789abcdefghijklmnopqrstuvwxyz upper=0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ
+[ "$bits" -gt 0 ] && [ "$bits" -le 64 ] || bits=64
+case $s in
+  -*) neg=1; s=${s#-} ;;
+  +*) s=${s#+} ;;
+esac
+if [ "$base" -eq 0 ]; then
+  base=10
+  case $s in
+    0[xX]*) base=16; s=${s#??} ;;
+    0[oO]*) base=8; s=${s#??} ;;
+    0[bB]*) base=2; s=${s#??} ;;
+    0?*) base=8; s=${s#0} ;;
+  esac
+  case $s in
+    _*|*_|*__*) printf '0\n'; return 1 ;;
+  esac
+  while :; do
+    case $s in
+      *_*) s=${s%%_*}${s#*_} ;;
+      *) break ;;
+    esac
+  done
+fi
+if [ -z "$s" ] || [ "$base" -lt 2 ] || [ "$base" -gt 36 ]; then
+  printf '0\n'
+  return 1
+fi
+if [ -n "$neg" ]; then
+  lim=$(( -(1 << (bits - 2)) * 2 ))
+else
+  lim=$(( -((1 << (bits - 2)) * 2 - 1) ))
+fi
+while [ -n "$s" ]; do
+  c=${s%"${s#?}"}
+  s=${s#?}
+  d=${digits%%"$c"*}
+  [ "${#d}" -lt 36 ] || d=${upper%%"$c"*}
+  d=${#d}
+  if [ "$d" -ge "$base" ]; then
+    printf '0\n'
+    return 1
+  fi
+  if [ "$v" -lt $(( (lim + d) / base )) ]; then
+    [ -n "$neg" ] && printf '%d\n' "$lim" || printf '%d\n' $(( -lim ))
+    return 2
+  fi
+  v=$(( v * base - d ))
+done
+[ -n "$neg" ] && printf '%d\n' "$v" || printf '%d\n' $(( -v ))
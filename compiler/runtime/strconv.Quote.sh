@@ -0,0 +1,30 @@
+local s="$1" r= c code
+case $s in
+  *[[:cntrl:]\"\\]*) ;;
+  *)
+    printf '"%s"\n' "$s"
+    return 0
+    ;;
+esac
+while [ -n "$s" ]; do
+  c=${s%"${s#?}"}
+  s=${s#?}
+  case $c in
+    \"|\\) r=$r\\$c ;;
+    [[:cntrl:]])
+      code=$(printf '%s' "$c" | od -An -tx1 | tr -d ' \n')
+      case $code in
+        07) r=$r'\a' ;;
+        08) r=$r'\b' ;;
+        0c) r=$r'\f' ;;
+        0a) r=$r'\n' ;;
+        0d) r=$r'\r' ;;
+        09) r=$r'\t' ;;
+        0b) r=$r'\v' ;;
+        *) r=$r'\x'$code ;;
+      esac
+      ;;
+    *) r=$r$c ;;
+  esac
+done
+printf '"%s"\n' "$r"
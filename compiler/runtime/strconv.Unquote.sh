@@ -0,0 +1,73 @@
+local s="$1" q c n h r=
+GOTOSH_RET_0=
+q=${s:0:1}
+case $q in
+  \`|\"|\') ;;
+  *) return 1 ;;
+esac
+[ "${#s}" -ge 2 ] && [ "${s: -1}" = "$q" ] || return 1
+s=${s:1:${#s}-2}
+if [ "$q" = \` ]; then
+  case $s in
+    *\`*) return 1 ;;
+  esac
+  GOTOSH_RET_0=${s//$'\r'/}
+  return 0
+fi
+while [ -n "$s" ]; do
+  c=${s:0:1}
+  s=${s:1}
+  case $c in
+    "$q"|$'\n') return 1 ;;
+    \\)
+      c=${s:0:1}
+      s=${s:1}
+      case $c in
+        a) c=$'\a' ;;
+        b) c=$'\b' ;;
+        f) c=$'\f' ;;
+        n) c=$'\n' ;;
+        r) c=$'\r' ;;
+        t) c=$'\t' ;;
+        v) c=$'\v' ;;
+        \\) ;;
+        "$q") ;;
+        x|u|U)
+          case $c in
+            x) n=2 ;;
+            u) n=4 ;;
+            U) n=8 ;;
+          esac
+          h=${s:0:$n}
+          s=${s:$n}
+          case $h in
+            *[!0-9a-fA-F]*) return 1 ;;
+          esac
+          [ "${#h}" -eq "$n" ] || return 1
+          if [ "$c" = x ]; then
+            printf -v c "\\x$h"
+          else
+            GOTOSH_RT_utf8__encode $((16#$h))
+            c=$GOTOSH_RET_0
+            GOTOSH_RET_0=
+          fi
+          ;;
+        [0-7])
+          n=$c${s:0:2}
+          case $n in
+            [0-3][0-7][0-7]) ;;
+            *) return 1 ;;
+          esac
+          printf -v c "\\$n"
+          s=${s:2}
+          ;;
+        *) return 1 ;;
+      esac
+      ;;
+  esac
+  r=$r$c
+done
+if [ "$q" = \' ] && [ "${#r}" -ne 1 ]; then
+  return 1
+fi
+GOTOSH_RET_0=$r
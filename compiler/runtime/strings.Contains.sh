@@ -1,4 +1,4 @@
 case "$1" in
-  *"$2"*) printf '1\n' ;;
-  *) printf '0\n' ;;
+  *"$2"*) GOTOSH_RET_0=1 ;;
+  *) GOTOSH_RET_0=0 ;;
 esac
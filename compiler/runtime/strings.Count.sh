@@ -0,0 +1,12 @@
+local s="$1" n=0
+if [ -z "$2" ]; then
+  GOTOSH_RET_0=$(( ${#s} + 1 ))
+  return 0
+fi
+while :; do
+  case "$s" in
+    *"$2"*) s=${s#*"$2"}; n=$((n + 1)) ;;
+    *) break ;;
+  esac
+done
+GOTOSH_RET_0=$n
@@ -0,0 +1,4 @@
+case "$1" in
+  *"$2"*) GOTOSH_RET_0=${1%%"$2"*}; GOTOSH_RET_1=${1#*"$2"}; GOTOSH_RET_2=1 ;;
+  *) GOTOSH_RET_0=$1; GOTOSH_RET_1=; GOTOSH_RET_2=0 ;;
+esac
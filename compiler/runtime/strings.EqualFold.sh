@@ -0,0 +1,5 @@
+if [ "${1,,}" = "${2,,}" ]; then
+  GOTOSH_RET_0=1
+else
+  GOTOSH_RET_0=0
+fi
@@ -0,0 +1,9 @@
+local s="$1" f
+GOTOSH_RET_0=()
+while :; do
+  s=${s#"${s%%[![:space:]]*}"}
+  [ -n "$s" ] || break
+  f=${s%%[[:space:]]*}
+  s=${s#"$f"}
+  GOTOSH_RET_0+=("$f")
+done
@@ -3,8 +3,8 @@ i=0
 while [ "$i" -lt "${#s}" ]; do
   ch=${s:$i:1}
   case "$chars" in
-    *"$ch"*) printf '%d\n' "$i"; return 0 ;;
+    *"$ch"*) GOTOSH_RET_0=$i; return 0 ;;
   esac
   i=$((i + 1))
 done
-printf '%d\n' -1
+GOTOSH_RET_0=-1
@@ -0,0 +1,9 @@
+local n=$(($# - 1)) sep="${@: -1}"
+GOTOSH_RET_0=
+[ "$n" -gt 0 ] || return 0
+GOTOSH_RET_0=$1
+while [ "$n" -gt 1 ]; do
+  shift
+  GOTOSH_RET_0=$GOTOSH_RET_0$sep$1
+  n=$((n - 1))
+done
@@ -0,0 +1,12 @@
+local r= c code i=0
+while [ "$i" -lt "${#2}" ]; do
+  c=${2:$i:1}
+  printf -v code '%d' "'$c"
+  code=$("$1" "$code")
+  if [ "$code" -ge 0 ]; then
+    GOTOSH_RT_utf8__encode "$code"
+    r=$r$GOTOSH_RET_0
+  fi
+  i=$((i + 1))
+done
+GOTOSH_RET_0=$r
@@ -0,0 +1,7 @@
+local s="$1" n="$2" r=
+while [ "$n" -gt 0 ]; do
+  [ $((n % 2)) -eq 0 ] || r=$r$s
+  s=$s$s
+  n=$((n / 2))
+done
+GOTOSH_RET_0=$r
@@ -0,0 +1,19 @@
+local s="$1" r= n="$4"
+if [ -z "$2" ]; then
+  while [ "$n" -ne 0 ]; do
+    r=$r$3
+    [ -n "$s" ] || break
+    r=$r${s:0:1}
+    s=${s:1}
+    n=$((n - 1))
+  done
+  GOTOSH_RET_0=$r$s
+  return 0
+fi
+while [ "$n" -ne 0 ]; do
+  case "$s" in
+    *"$2"*) r=$r${s%%"$2"*}$3; s=${s#*"$2"}; n=$((n - 1)) ;;
+    *) break ;;
+  esac
+done
+GOTOSH_RET_0=$r$s
@@ -0,0 +1,16 @@
+local s="$1" i=0
+GOTOSH_RET_0=()
+if [ -z "$2" ]; then
+  while [ "$i" -lt "${#s}" ]; do
+    GOTOSH_RET_0+=("${s:$i:1}")
+    i=$((i + 1))
+  done
+  return 0
+fi
+while :; do
+  case "$s" in
+    *"$2"*) GOTOSH_RET_0+=("${s%%"$2"*}"); s=${s#*"$2"} ;;
+    *) break ;;
+  esac
+done
+GOTOSH_RET_0+=("$s")
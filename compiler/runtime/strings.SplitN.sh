@@ -0,0 +1,17 @@
+local s="$1" n="$3"
+GOTOSH_RET_0=()
+[ "$n" -ne 0 ] || return 0
+while [ "$n" -ne 1 ]; do
+  if [ -z "$2" ]; then
+    [ "${#s}" -gt 1 ] || break
+    GOTOSH_RET_0+=("${s:0:1}")
+    s=${s:1}
+  else
+    case "$s" in
+      *"$2"*) GOTOSH_RET_0+=("${s%%"$2"*}"); s=${s#*"$2"} ;;
+      *) break ;;
+    esac
+  fi
+  n=$((n - 1))
+done
+[ -z "$2" ] && [ -z "$s" ] || GOTOSH_RET_0+=("$s")
@@ -0,0 +1,11 @@
+local s="$1" r= c prev=' ' i=0
+while [ "$i" -lt "${#s}" ]; do
+  c=${s:$i:1}
+  case "$prev" in
+    [[:alnum:]_]) r=$r$c ;;
+    *) r=$r${c^} ;;
+  esac
+  prev=$c
+  i=$((i + 1))
+done
+GOTOSH_RET_0=$r
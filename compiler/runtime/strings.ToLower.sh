@@ -1 +1 @@
-printf '%s\n' "$1" | tr '[:upper:]' '[:lower:]'
+GOTOSH_RET_0=${1,,}
@@ -1 +1 @@
-printf '%s\n' "$1" | tr '[:lower:]' '[:upper:]'
+GOTOSH_RET_0=${1^^}
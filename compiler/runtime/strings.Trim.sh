@@ -0,0 +1,14 @@
+local s="$1"
+while [ -n "$s" ]; do
+  case "$2" in
+    *"${s:0:1}"*) s=${s:1} ;;
+    *) break ;;
+  esac
+done
+while [ -n "$s" ]; do
+  case "$2" in
+    *"${s: -1}"*) s=${s%?} ;;
+    *) break ;;
+  esac
+done
+GOTOSH_RET_0=$s
@@ -1 +1 @@
-printf '%s\n' "${1#"$2"}"
+GOTOSH_RET_0=${1#"$2"}
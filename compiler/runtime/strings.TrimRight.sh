@@ -0,0 +1,8 @@
+local s="$1"
+while [ -n "$s" ]; do
+  case "$2" in
+    *"${s: -1}"*) s=${s%?} ;;
+    *) break ;;
+  esac
+done
+GOTOSH_RET_0=$s
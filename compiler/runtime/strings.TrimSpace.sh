@@ -1 +1,2 @@
-printf '%s\n' "$1" | sed -e 's/^[[:space:]]*//' -e 's/[[:space:]]*$//'
+GOTOSH_RET_0=${1#"${1%%[![:space:]]*}"}
+GOTOSH_RET_0=${GOTOSH_RET_0%"${GOTOSH_RET_0##*[![:space:]]}"}
@@ -0,0 +1,25 @@
+case $1 in
+  1) echo hangup ;;
+  2) echo interrupt ;;
+  3) echo quit ;;
+  4) echo illegal instruction ;;
+  5) echo trace/breakpoint trap ;;
+  6) echo aborted ;;
+  7) echo bus error ;;
+  8) echo floating point exception ;;
+  9) echo killed ;;
+  10) echo user defined signal 1 ;;
+  11) echo segmentation fault ;;
+  12) echo user defined signal 2 ;;
+  13) echo broken pipe ;;
+  14) echo alarm clock ;;
+  15) echo terminated ;;
+  17) echo child exited ;;
+  18) echo continued ;;
+  19) echo 'stopped (signal)' ;;
+  20) echo stopped ;;
+  21) echo 'stopped (tty input)' ;;
+  22) echo 'stopped (tty output)' ;;
+  28) echo window changed ;;
+  *) echo "signal $1" ;;
+esac
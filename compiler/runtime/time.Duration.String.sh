@@ -0,0 +1,26 @@
+local d=$1 sign= unit=s prec=9 p=1000000000 w f out
+if [ "$d" -eq 0 ]; then
+  printf '0s\n'
+  return
+elif [ "$d" -lt 0 ]; then
+  sign=- d=$(( -d ))
+fi
+if [ "$d" -lt 1000 ]; then
+  printf '%s%sns\n' "$sign" "$d"
+  return
+elif [ "$d" -lt 1000000 ]; then
+  unit=µs prec=3 p=1000
+elif [ "$d" -lt 1000000000 ]; then
+  unit=ms prec=6 p=1000000
+fi
+w=$(( d / p )) f=$(( d % p ))
+while [ "${#f}" -lt "$prec" ]; do f=0$f; done
+f=${f%"${f##*[!0]}"}
+f=${f:+.$f}
+if [ "$unit" != s ] || [ "$w" -lt 60 ]; then
+  printf '%s%s%s%s\n' "$sign" "$w" "$f" "$unit"
+  return
+fi
+out="$(( w / 60 % 60 ))m$(( w % 60 ))$f"s
+[ "$w" -lt 3600 ] || out="$(( w / 3600 ))h$out"
+printf '%s%s\n' "$sign" "$out"
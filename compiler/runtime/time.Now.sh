@@ -0,0 +1,11 @@
+local t
+if [ -n "${EPOCHREALTIME-}" ]; then
+  t=${EPOCHREALTIME/[.,]/}
+  printf '%s\n' "$(( 10#$t * 1000 ))"
+  return
+fi
+t=$(date +%s%N)
+case $t in
+  *[!0-9]*) printf '%s\n' "$(( $(date +%s) * 1000000000 ))" ;;
+  *) printf '%s\n' "$t" ;;
+esac
@@ -0,0 +1,140 @@
+local layout=$1 value=$2 year=1 mon=1 day=1 yday=0 hour=0 min=0 sec=0 ns=0 ampm= zone= off= loc= re k n v
+local months=" January February March April May June July August September October November December"
+while [ -n "$layout" ]; do
+  re= k= n=0
+  case $layout in
+    January*) re='^(January|February|March|April|May|June|July|August|September|October|November|December)' k=month n=7 ;;
+    Jan*) [[ ${layout:3:1} == [a-z] ]] || re='^(Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)' k=month n=3 ;;
+    Monday*) re='^(Monday|Tuesday|Wednesday|Thursday|Friday|Saturday|Sunday)' n=6 ;;
+    Mon*) [[ ${layout:3:1} == [a-z] ]] || re='^(Mon|Tue|Wed|Thu|Fri|Sat|Sun)' n=3 ;;
+    MST*) re='^([A-Z][A-Za-z]{2,4}([+-][0-9]{1,2})?)' k=zone n=3 ;;
+    01*) re='^([0-9]{2})' k=mon n=2 ;;
+    02*) re='^([0-9]{2})' k=day n=2 ;;
+    03*) re='^([0-9]{2})' k=hour12 n=2 ;;
+    04*) re='^([0-9]{2})' k=min n=2 ;;
+    05*) re='^([0-9]{2})' k=sec n=2 ;;
+    06*) re='^([0-9]{2})' k=year2 n=2 ;;
+    002*) re='^([0-9]{3})' k=yday n=3 ;;
+    15*) re='^([0-9]{1,2})' k=hour n=2 ;;
+    1*) re='^([0-9]{1,2})' k=mon n=1 ;;
+    2006*) re='^([0-9]{4})' k=year n=4 ;;
+    2*) re='^([0-9]{1,2})' k=day n=1 ;;
+    _2006*) re='^_([0-9]{4})' k=year n=5 ;;
+    __2*) re='^ {0,2}([0-9]{1,3})' k=yday n=3 ;;
+    _2*) re='^ ?([0-9]{1,2})' k=day n=2 ;;
+    3*) re='^([0-9]{1,2})' k=hour12 n=1 ;;
+    4*) re='^([0-9]{1,2})' k=min n=1 ;;
+    5*) re='^([0-9]{1,2})' k=sec n=1 ;;
+    PM*) re='^(AM|PM)' k=ampm n=2 ;;
+    pm*) re='^(am|pm)' k=ampm n=2 ;;
+    Z0700* | Z07:00* | Z07* | -0700* | -07:00* | -07*)
+      case $layout in
+        ?0700*) re='^([+-])([0-9]{2})([0-9]{2})' n=5 ;;
+        ?07:00*) re='^([+-])([0-9]{2}):([0-9]{2})' n=6 ;;
+        *) re='^([+-])([0-9]{2})()' n=3 ;;
+      esac
+      k=offset
+      if [ "${layout:0:1}" = Z ] && [ "${value:0:1}" = Z ]; then
+        re='^Z' k=utc
+      fi
+      ;;
+    [.,]0* | [.,]9*)
+      v=${layout:1}
+      v=${v%%[!${layout:1:1}]*}
+      if [[ ${layout:1+${#v}:1} != [0-9] ]]; then
+        n=$(( 1 + ${#v} ))
+        if [ "${layout:1:1}" = 0 ]; then
+          re="^[.,]([0-9]{${#v}})" k=frac
+        else
+          re='^([.,]([0-9]+))?' k=frac9
+        fi
+      fi
+      ;;
+  esac
+  if [ "$n" -eq 0 ]; then
+    if [ "${layout:0:1}" = ' ' ]; then
+      [ -z "$value" ] || [ "${value:0:1}" = ' ' ] || return 1
+      layout=${layout#"${layout%%[! ]*}"}
+      value=${value#"${value%%[! ]*}"}
+      continue
+    fi
+    [ "${layout:0:1}" = "${value:0:1}" ] || return 1
+    layout=${layout:1} value=${value:1}
+    continue
+  fi
+  [[ $value =~ $re ]] || return 1
+  value=${value:${#BASH_REMATCH[0]}}
+  layout=${layout:n}
+  v=${BASH_REMATCH[1]-}
+  case $k in
+    month)
+      v=${months%%" $v"*}
+      v=${v//[! ]/}
+      mon=$(( ${#v} + 1 ))
+      ;;
+    mon | day | yday | min) printf -v "$k" '%d' "$(( 10#$v ))" ;;
+    hour | hour12) hour=$(( 10#$v )) ;;
+    sec)
+      sec=$(( 10#$v ))
+      # A fractional second is accepted even when the layout has none.
+      if [[ $layout != [.,][09]* && $value =~ ^[.,]([0-9]+) ]]; then
+        v=${BASH_REMATCH[1]}000000000
+        ns=$(( 10#${v:0:9} ))
+        value=${value:${#BASH_REMATCH[0]}}
+      fi
+      ;;
+    year) year=$(( 10#$v )) ;;
+    year2) year=$(( 10#$v >= 69 ? 1900 + 10#$v : 2000 + 10#$v )) ;;
+    ampm) ampm=${v^^} ;;
+    zone) zone=$v ;;
+    utc) off=0 loc=UTC ;;
+    offset) off=$(( (10#${BASH_REMATCH[2]} * 3600 + 10#${BASH_REMATCH[3]:-0} * 60) * (${BASH_REMATCH[1]}1) )) ;;
+    frac | frac9)
+      v=${BASH_REMATCH[2]-$v}000000000
+      ns=$(( 10#${v:0:9} ))
+      ;;
+  esac
+  case $k in
+    hour) [ "$hour" -lt 24 ] || return 2 ;;
+    hour12) [ "$hour" -le 12 ] || return 2 ;;
+    min | sec) [ "${!k}" -lt 60 ] || return 2 ;;
+    mon) [ "$mon" -ge 1 ] && [ "$mon" -le 12 ] || return 2 ;;
+    day) [ "$day" -le 31 ] || return 2 ;;
+  esac
+done
+[ -z "$value" ] || return 1
+if [ "$ampm" = PM ] && [ "$hour" -lt 12 ]; then
+  hour=$(( hour + 12 ))
+elif [ "$ampm" = AM ] && [ "$hour" -eq 12 ]; then
+  hour=0
+fi
+local leap=$(( year % 4 == 0 && (year % 100 != 0 || year % 400 == 0) ))
+local mdays=(0 31 $(( 28 + leap )) 31 30 31 30 31 31 30 31 30 31)
+if [ "$yday" -gt 0 ]; then
+  [ "$yday" -le $(( 365 + leap )) ] || return 1
+  mon=1 day=$yday
+  while [ "$day" -gt "${mdays[mon]}" ]; do
+    day=$(( day - mdays[mon] )) mon=$(( mon + 1 ))
+  done
+fi
+[ "$day" -ge 1 ] && [ "$day" -le "${mdays[mon]}" ] || return 1
+local y=$(( year - (mon <= 2) ))
+local era=$(( (y >= 0 ? y : y - 399) / 400 ))
+local yoe=$(( y - era * 400 ))
+local doy=$(( (153 * (mon + (mon > 2 ? -3 : 9)) + 2) / 5 + day - 1 ))
+local t=$(( (era * 146097 + yoe * 365 + yoe / 4 - yoe / 100 + doy - 719468) * 86400 + hour * 3600 + min * 60 + sec ))
+# the abbreviation or the offset of the local zone makes a local time
+if [ -z "$off" ] && [ -n "$zone" ] && [ "$zone" != UTC ] && [ "$zone" = "$(GOTOSH_RT_time__date "$t" %Z "")" ]; then
+  v=$(GOTOSH_RT_time__date "$t" %z "")
+  off=$(( (10#${v:1:2} * 3600 + 10#${v:3:2} * 60) * (${v:0:1}1) )) loc=Local
+elif [ -n "$off" ] && [ -z "$loc" ]; then
+  v=$(GOTOSH_RT_time__date "$(( t - off ))" %z "")
+  [ "$(( (10#${v:1:2} * 3600 + 10#${v:3:2} * 60) * (${v:0:1}1) ))" -ne "$off" ] || loc=Local
+fi
+t=$(( (t - ${off:-0}) * 1000000000 + ns ))
+if [ "$loc" = Local ]; then
+  printf '%s\n' "$t"
+else
+  # A time in UTC or a fixed zone keeps the offset in seconds: (ns+0*offset) evaluates to the nanoseconds.
+  printf '(%s+0*%s)\n' "$t" "${off:-0}"
+fi
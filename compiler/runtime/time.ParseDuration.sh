@@ -0,0 +1,38 @@
+local s=$1 neg= d=0 num frac unit u
+case $s in
+  -*) neg=1; s=${s#-} ;;
+  +*) s=${s#+} ;;
+esac
+if [ "$s" = 0 ]; then
+  printf '0\n'
+  return 0
+fi
+[ -n "$s" ] || { printf '0\n'; return 1; }
+while [ -n "$s" ]; do
+  # [0-9]*(.[0-9]*)? followed by a unit
+  num=${s%%[!0-9]*} s=${s#"${s%%[!0-9]*}"} frac=
+  case $s in
+    .*) s=${s#.} frac=${s%%[!0-9]*} s=${s#"${s%%[!0-9]*}"} ;;
+  esac
+  unit=${s%%[0-9.]*} s=${s#"${s%%[0-9.]*}"}
+  [ -n "$num$frac" ] && [ -n "$unit" ] || { printf '0\n'; return 1; }
+  case $unit in
+    ns) u=1 ;;
+    us|µs|μs) u=1000 ;;
+    ms) u=1000000 ;;
+    s) u=1000000000 ;;
+    m) u=60000000000 ;;
+    h) u=3600000000000 ;;
+    *) printf '0\n'; return 1 ;;
+  esac
+  num=${num#"${num%%[!0]*}"}
+  d=$(( d + ${num:-0} * u ))
+  # Add the fraction digit by digit so that large units do not overflow.
+  while [ -n "$frac" ] && [ "$u" -gt 1 ]; do
+    u=$(( u / 10 ))
+    d=$(( d + ${frac%"${frac#?}"} * u ))
+    frac=${frac#?}
+  done
+done
+[ -z "$neg" ] || d=$(( -d ))
+printf '%d\n' "$d"
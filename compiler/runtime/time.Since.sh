@@ -0,0 +1,3 @@
+local now
+now=$(GOTOSH_RT_time__Now)
+printf '%s\n' "$(( now - $1 ))"
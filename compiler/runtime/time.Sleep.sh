@@ -0,0 +1,4 @@
+local frac
+[ "$1" -gt 0 ] || return 0
+printf -v frac '%09d' "$(( $1 % 1000000000 ))"
+sleep "$(( $1 / 1000000000 )).$frac"
@@ -0,0 +1,5 @@
+# the offset of a time in UTC or a fixed zone is kept (see time.Parse)
+case $1 in
+  *\**) printf '(%s+0*%s\n' "$(( $1 + $2 ))" "${1##*\*}" ;;
+  *) printf '%s\n' "$(( $1 + $2 ))" ;;
+esac
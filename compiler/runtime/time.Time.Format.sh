@@ -0,0 +1,88 @@
+local sec=$(( $1 / 1000000000 )) ns=$(( $1 % 1000000000 )) layout=$2 f= n c z= frac tz=
+# a time in UTC or a fixed zone is formatted in the zone of the offset (see time.Parse)
+case $1 in
+  *\**)
+    c=${1##*\*}
+    c=${c%)}
+    tz=UTC
+    if [ "$c" -ne 0 ]; then
+      n=$(( c < 0 ? -c : c ))
+      printf -v n '%02d%02d' $(( n / 3600 )) $(( n / 60 % 60 ))
+      # the sign of the POSIX TZ is the opposite of the offset, e.g. <+0900>-09:00
+      if [ "$c" -lt 0 ]; then
+        tz="<-$n>+${n:0:2}:${n:2}"
+      else
+        tz="<+$n>-${n:0:2}:${n:2}"
+      fi
+    fi
+    ;;
+esac
+if [ "$ns" -lt 0 ]; then
+  sec=$(( sec - 1 )) ns=$(( ns + 1000000000 ))
+fi
+printf -v frac '%09d' "$ns"
+while [ -n "$layout" ]; do
+  n=0
+  case $layout in
+    January*) f+=%B n=7 ;;
+    Jan*) [[ ${layout:3:1} == [a-z] ]] || f+=%b n=3 ;;
+    Monday*) f+=%A n=6 ;;
+    Mon*) [[ ${layout:3:1} == [a-z] ]] || f+=%a n=3 ;;
+    MST*) f+=%Z n=3 ;;
+    01*) f+=%m n=2 ;;
+    02*) f+=%d n=2 ;;
+    03*) f+=%I n=2 ;;
+    04*) f+=%M n=2 ;;
+    05*) f+=%S n=2 ;;
+    06*) f+=%y n=2 ;;
+    002*) f+=%j n=3 ;;
+    15*) f+=%H n=2 ;;
+    1*) f+=%-m n=1 ;;
+    2006*) f+=%Y n=4 ;;
+    2*) f+=%-d n=1 ;;
+    _2006*) f+=_%Y n=5 ;;
+    __2*) f+=%_j n=3 ;;
+    _2*) f+=%e n=2 ;;
+    3*) f+=%-I n=1 ;;
+    4*) f+=%-M n=1 ;;
+    5*) f+=%-S n=1 ;;
+    PM*) f+=%p n=2 ;;
+    pm*) f+=%P n=2 ;;
+    Z0700* | Z07:00* | Z07* | -0700* | -07:00* | -07*)
+      c=${layout%%[!-Z0-9:]*}
+      c=${c:0:6}
+      case $c in
+        ?0700*) n=5 c=%z ;;
+        ?07:00) n=6 c=%:z ;;
+        *) n=3 c=%:::z ;;
+      esac
+      if [ "${layout:0:1}" = Z ]; then
+        [ -n "$z" ] || z=$(GOTOSH_RT_time__date "$sec" %z "$tz")
+        [ "$z" != +0000 ] || c=Z
+      fi
+      f+=$c
+      ;;
+    [.,]0* | [.,]9*)
+      c=${layout:1}
+      c=${c%%[!${layout:1:1}]*}
+      if [[ ${layout:1+${#c}:1} != [0-9] ]]; then
+        n=$(( 1 + ${#c} ))
+        if [ "${layout:1:1}" = 0 ]; then
+          f+=${layout:0:1}${frac:0:${#c}}
+        else
+          c=${frac:0:${#c}}
+          c=${c%"${c##*[!0]}"}
+          [ -z "$c" ] || f+=${layout:0:1}$c
+        fi
+      fi
+      ;;
+  esac
+  if [ "$n" -eq 0 ]; then
+    c=${layout:0:1}
+    [ "$c" != % ] || c=%%
+    f+=$c
+    n=1
+  fi
+  layout=${layout:n}
+done
+GOTOSH_RT_time__date "$sec" "$f" "$tz"
@@ -0,0 +1,3 @@
+# Runs date with the format $2 for the Unix time $1 in the TZ $3, or the local zone if it is empty.
+[ -z "$3" ] || local -x TZ="$3"
+LC_ALL=C date -d "@$1" +"$2" 2>/dev/null || LC_ALL=C date -r "$1" +"$2"
@@ -0,0 +1,11 @@
+local c="$1" e
+if [ "$c" -lt 128 ]; then
+  printf -v e '\\x%02x' "$c"
+elif [ "$c" -lt 2048 ]; then
+  printf -v e '\\x%02x\\x%02x' $((0xc0 | c >> 6)) $((0x80 | c & 63))
+elif [ "$c" -lt 65536 ]; then
+  printf -v e '\\x%02x\\x%02x\\x%02x' $((0xe0 | c >> 12)) $((0x80 | c >> 6 & 63)) $((0x80 | c & 63))
+else
+  printf -v e '\\x%02x\\x%02x\\x%02x\\x%02x' $((0xf0 | c >> 18)) $((0x80 | c >> 12 & 63)) $((0x80 | c >> 6 & 63)) $((0x80 | c & 63))
+fi
+printf -v GOTOSH_RET_0 "$e"
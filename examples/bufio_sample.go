@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+func countWords(scanner *bufio.Scanner) int {
+	n := 0
+	for scanner.Scan() {
+		n++
+	}
+	return n
+}
+
+func main() {
+	path := "bufio_sample.txt"
+	os.WriteFile(path, []byte("first line\n  back\\slash *  \r\n\n\tlast line without newline"), 0644)
+
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Println("open error")
+		return
+	}
+	scanner := bufio.NewScanner(f)
+	for i := 1; scanner.Scan(); i++ {
+		fmt.Printf("line %d: %q\n", i, scanner.Text())
+	}
+	fmt.Println("err", scanner.Err() == nil)
+	f.Close()
+
+	f, _ = os.Open(path)
+	scanner = bufio.NewScanner(f)
+	scanner.Split(bufio.ScanWords)
+	for i := 1; i <= 3; i++ {
+		if !scanner.Scan() {
+			break
+		}
+		fmt.Printf("word %d: %q\n", i, scanner.Text())
+	}
+	fmt.Println("rest", countWords(scanner))
+	f.Close()
+
+	f, _ = os.Open(path)
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		fmt.Printf("read %q %v\n", line, err == nil)
+		if err == io.EOF {
+			break
+		}
+	}
+	f.Close()
+
+	f, _ = os.Open(path)
+	reader = bufio.NewReader(f)
+	s, err := reader.ReadString(' ')
+	fmt.Printf("until space %q %v\n", s, err == nil)
+	f.Close()
+	os.Remove(path)
+}
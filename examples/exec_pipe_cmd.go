@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/binzume/gotosh/shell"
+)
+
+func produce(_ *os.File, out *os.File, _ ...string) shell.StatusCode {
+	fmt.Fprintln(out, "beta")
+	fmt.Fprintln(out, "alpha two")
+	fmt.Fprintln(out, "gamma")
+	fmt.Fprintln(out, "alpha two")
+	return 0
+}
+
+func upper(in, out *os.File, _ ...string) shell.StatusCode {
+	for {
+		line, status := shell.ReadLine(in)
+		if status != 0 {
+			return 0
+		}
+		fmt.Fprintln(out, strings.ToUpper(line))
+	}
+}
+
+func main() {
+	shell.ExecPipe(
+		shell.Bind(produce),
+		shell.Cmd("grep", "-v", "gamma"),
+		shell.Bind(upper),
+		shell.Cmd("sort", "-u"),
+	)
+
+	cmd := exec.Command("tr", "a-z", "A-Z")
+	status := shell.ExecPipe(
+		shell.Cmd("printf", "%s\n", "it's a b"),
+		shell.BindCmd(cmd),
+	)
+	fmt.Println("status", status)
+
+	status = shell.ExecPipe(
+		shell.Bind(produce),
+		shell.Cmd("grep", "-q", "delta"),
+	)
+	fmt.Println("status", status)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/binzume/gotosh/shell"
+)
+
+func produce(_ *os.File, out *os.File, _ ...string) shell.StatusCode {
+	fmt.Fprintln(out, "alpha")
+	fmt.Fprintln(out, "beta")
+	return 3
+}
+
+func count(in, out *os.File, _ ...string) shell.StatusCode {
+	n := 0
+	for {
+		_, status := shell.ReadLine(in)
+		if status != 0 {
+			break
+		}
+		n++
+	}
+	fmt.Fprintln(out, "lines", n)
+	return 0
+}
+
+func main() {
+	statuses := shell.ExecPipeAll(
+		shell.Bind(produce),
+		shell.Cmd("grep", "-v", "beta"),
+		shell.Bind(count),
+	)
+	fmt.Println(len(statuses), statuses)
+	for i, st := range statuses {
+		if st != 0 {
+			fmt.Println("stage", i, "failed with", st)
+		}
+	}
+
+	status := shell.ExecPipe(shell.Bind(produce), shell.Bind(count))
+	fmt.Println("last", status)
+}
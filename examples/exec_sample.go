@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func main() {
+	out, err := exec.Command("printf", "[%s]\n", "a b", "$(echo injected)", "it's \"quoted\"", "*").Output()
+	fmt.Print(string(out))
+	fmt.Println("output", err == nil)
+
+	out, err = exec.Command("sh", "-c", "echo out; echo err >&2; exit 3").CombinedOutput()
+	fmt.Printf("combined %q\n", string(out))
+	if ee, ok := err.(*exec.ExitError); ok {
+		fmt.Println("exit code", ee.ExitCode())
+	}
+
+	err = exec.Command("true").Run()
+	fmt.Println("run true", err == nil)
+
+	cmd := exec.Command("sh", "-c", "echo \"$GREETING from $(basename \"$PWD\")\"")
+	cmd.Env = append(os.Environ(), "GREETING=hello")
+	cmd.Dir = "/tmp"
+	cmd.Stdout = os.Stdout
+	err = cmd.Run()
+	fmt.Println("run", err == nil)
+
+	os.WriteFile("exec_sample_in.txt", []byte("b\na\nc\n"), 0644)
+	in, _ := os.Open("exec_sample_in.txt")
+	sorted, _ := os.Create("exec_sample_out.txt")
+	sortCmd := exec.Command("sort")
+	sortCmd.Stdin = in
+	sortCmd.Stdout = sorted
+	err = sortCmd.Start()
+	fmt.Println("start", err == nil, sortCmd.Process.Pid > 0)
+	err = sortCmd.Wait()
+	in.Close()
+	sorted.Close()
+	data, _ := os.ReadFile("exec_sample_out.txt")
+	fmt.Println("wait", err == nil, strings.Fields(string(data)))
+	os.Remove("exec_sample_in.txt")
+	os.Remove("exec_sample_out.txt")
+
+	_, err = exec.LookPath("sh")
+	fmt.Println("lookpath sh", err == nil)
+	_, err = exec.LookPath("no-such-command-gotosh")
+	fmt.Println("lookpath missing", err != nil)
+	err = exec.Command("no-such-command-gotosh").Run()
+	fmt.Println("run missing", err != nil)
+
+	cmd = exec.Command("./sh", "-c", "echo relative")
+	cmd.Dir = "/bin"
+	out, err = cmd.Output()
+	fmt.Println("relative", strings.TrimSpace(string(out)), err == nil)
+}
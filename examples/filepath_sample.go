@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+func rel(base, target string) {
+	r, err := filepath.Rel(base, target)
+	fmt.Printf("Rel(%q, %q) = %q %v\n", base, target, r, err == nil)
+}
+
+func match(pattern, name string) {
+	ok, err := filepath.Match(pattern, name)
+	fmt.Printf("Match(%q, %q) = %v %v\n", pattern, name, ok, err == nil)
+}
+
+func main() {
+	for _, p := range []string{"", ".", "/", "a/b/../c", "//a//b/./", "../../x", "/../a", "a/..", "./a/", "a/b/..."} {
+		fmt.Printf("%q: Clean=%q Base=%q Dir=%q Ext=%q IsAbs=%v\n", p, filepath.Clean(p), filepath.Base(p), filepath.Dir(p), filepath.Ext(p), filepath.IsAbs(p))
+	}
+	fmt.Println(filepath.Join("a", "", "b/", "../c"), filepath.Join("", ""), filepath.Join("/", "x"), path.Join("a", "b"))
+	fmt.Println(path.Base("x/y.tar.gz"), path.Ext("x/y.tar.gz"), path.Dir("/x"), path.Clean("a//b"))
+
+	rel("a/b", "a/b/c/d")
+	rel("a/b/c", "a/x")
+	rel("/a", "/b/c")
+	rel(".", "a")
+	rel("a", ".")
+	rel("/a", "b")
+	rel("../a", "b")
+	rel("a", "a")
+	abs, err := filepath.Abs("/tmp/../usr")
+	fmt.Println("Abs", abs, err == nil)
+
+	match("*.go", "main.go")
+	match("*.go", "dir/main.go")
+	match("*/*.go", "dir/main.go")
+	match("a?c", "abc")
+	match("[a-c]x", "bx")
+	match("[^a-c]x", "bx")
+	match("[a-c]?[!x]", "bzy")
+	match("[a-c]?[!x]", "bz!")
+	match("[^!]", "!")
+	match("[\\]]", "]")
+	match("\\*", "*")
+	match("[", "a")
+	match("a\\", "a")
+	match("*", ".hidden")
+
+	root := "filepath_sample_tmp"
+	os.RemoveAll(root)
+	os.MkdirAll(root+"/b/c", 0755)
+	os.MkdirAll(root+"/a-b", 0755)
+	os.MkdirAll(root+"/skip/deep", 0755)
+	os.WriteFile(root+"/b/c/x.go", []byte("x"), 0644)
+	os.WriteFile(root+"/b/y.txt", []byte("yy"), 0644)
+	os.WriteFile(root+"/a-b/z.go", []byte("z"), 0644)
+	os.WriteFile(root+"/skip/deep/w.go", []byte("w"), 0644)
+	os.WriteFile(root+"/.hidden", []byte(""), 0644)
+
+	files, err := filepath.Glob(root + "/*/*.go")
+	fmt.Println("Glob", len(files), err == nil)
+	for _, f := range files {
+		fmt.Println(" ", f)
+	}
+	files, _ = filepath.Glob(root + "/*")
+	fmt.Println("Glob", files)
+	files, _ = filepath.Glob(root + "/b")
+	fmt.Println("Glob", files)
+	files, _ = filepath.Glob(root + "/[^b]*")
+	fmt.Println("Glob", files)
+	files, _ = filepath.Glob(root + "/[!a]*")
+	fmt.Println("Glob", files)
+	_, err = filepath.Glob("[")
+	fmt.Println("Glob bad", err == filepath.ErrBadPattern)
+
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == "skip" {
+			fmt.Println("skip", p)
+			return filepath.SkipDir
+		}
+		fmt.Println("walk", p, d.Name(), d.IsDir())
+		return nil
+	})
+	fmt.Println("WalkDir", err == nil)
+
+	err = filepath.Walk(root+"/b", func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		fmt.Println("info", p, info.Name(), info.Size(), info.IsDir())
+		if info.Name() == "c" {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	fmt.Println("Walk", err == nil)
+
+	err = filepath.WalkDir(root+"/missing", func(p string, d fs.DirEntry, err error) error {
+		fmt.Println("missing", p, os.IsNotExist(err))
+		return err
+	})
+	fmt.Println("WalkDir missing", os.IsNotExist(err))
+	os.RemoveAll(root)
+}
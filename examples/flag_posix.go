@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// flag_posix only uses the flag functions which work under POSIX sh: no pointers or slices.
+func main() {
+	var name string
+	flag.StringVar(&name, "name", "gopher", "`user` name to greet")
+	var count int
+	flag.IntVar(&count, "n", 1, "number of greetings")
+	var verbose bool
+	flag.BoolVar(&verbose, "v", false, "verbose output")
+	var wait time.Duration
+	flag.DurationVar(&wait, "wait", 90*time.Second, `time to wait
+between greetings`)
+	var prefix string
+	flag.StringVar(&prefix, "prefix", "", `message "prefix"`)
+
+	flag.CommandLine.SetOutput(os.Stdout)
+	flag.CommandLine.Parse([]string{"-name=it's me", "--n", "0x2", "-v", "-wait", "1h1.5s", "-prefix", "", "rest", "-x"})
+
+	fmt.Println(prefix+"hello", name, count)
+	if verbose {
+		fmt.Println("verbose")
+	}
+	fmt.Println("wait:", wait.String())
+	fmt.Println("nargs:", flag.NArg(), "first:", flag.Arg(0), "second:", flag.Arg(1), "third:", flag.Arg(2))
+	flag.PrintDefaults()
+}
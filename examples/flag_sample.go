@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+func main() {
+	name := flag.String("name", "gopher", "`user` name to greet")
+	count := flag.Int("n", 1, "number of greetings")
+	verbose := flag.Bool("v", false, "verbose output")
+	wait := flag.Duration("wait", 90*time.Second, "time to wait\nbetween greetings")
+	var level int
+	flag.IntVar(&level, "level", 0, "log level")
+	var prefix string
+	flag.StringVar(&prefix, "prefix", "", "message prefix")
+
+	flag.CommandLine.SetOutput(os.Stdout)
+	flag.CommandLine.Parse([]string{"-name=world", "--n", "3", "-v", "-level", "0x10", "-wait", "1.5s", "rest", "-x"})
+
+	for i := 0; i < *count; i++ {
+		fmt.Println(prefix+"hello", *name)
+	}
+	fmt.Println("verbose:", *verbose, "level:", level, "wait:", *wait)
+	fmt.Println("nargs:", flag.NArg(), "first:", flag.Arg(0))
+	for _, a := range flag.Args() {
+		fmt.Println("arg:", a)
+	}
+	flag.PrintDefaults()
+}
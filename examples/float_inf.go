@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+func main() {
+	y := math.Inf(1)
+	n := math.NaN()
+	fmt.Println(y > 1e308, y-1, -y, 1/y, y*2 == y, n < 1, n > 1)
+	fmt.Println(math.Min(2, n), math.Max(n, 2), math.Max(math.Inf(-1), 3), math.Min(math.Inf(-1), n), math.Max(n, y))
+	fmt.Println(math.IsInf(y-1, 1), math.IsInf(-y*3, -1), math.IsNaN(n*2), math.IsNaN(y-y))
+	fmt.Printf("%v %6.2f %g\n", y+1, -y, n+1)
+}
@@ -0,0 +1,52 @@
+package main
+
+import "fmt"
+
+type Point struct {
+	X, Y int
+}
+
+type Item struct {
+	Name  string
+	Count int
+	Pos   Point
+	Ok    bool
+	Price float64
+}
+
+func main() {
+	item := Item{"apple pie", 3, Point{1, 2}, true, 1.5}
+	fmt.Println(item)
+	fmt.Printf("%v|%+v|%#v|%T\n", item, item, item, item)
+
+	a := []int{1, 2, 3}
+	ss := []string{"a", "b c"}
+	fmt.Println(a, ss, len(a) > 2, 1 == 2)
+	fmt.Printf("%v|%#v|%q|%x|%d|%T\n", a, ss, ss, "hi", []int{-255}, a)
+
+	m := map[string]int{"b": 1, "a": 2}
+	fmt.Println(m, len(m))
+
+	fmt.Printf("%d|%s|%x|%X|%o|%b|%#x\n", true, 3, -255, 255, 8, 5, 255)
+	fmt.Printf("%5.2f|%-4d|%05d|%+d|%8v|%-8v|%t|%%\n", 3.14159, 7, -42, 5, "ab", true, false)
+	fmt.Printf("%c|%U|%.2s|%6.3v|%e|%g\n", 65, 0x1F600, "abcdef", 3.14159, 1234.5678, 0.000012)
+	fmt.Printf("%d %d\n", 1)
+	fmt.Printf("%d\n", 1, "extra")
+
+	x := 1.0 / 4
+	fmt.Println(x, 1e6, 123456.0, 2.25, x > 0)
+	fmt.Print("a", 1, 2, "b", 3.5, true, "\n")
+
+	var zero float64
+	var ok bool
+	fmt.Println(zero, ok, zero+1)
+	fmt.Printf("%v|%t|%T|%5.1f|%v\n", zero, ok, ok, zero, []float64{zero, x})
+
+	s := fmt.Sprintf("%05.1f|%v\n", 3.14159, ss)
+	fmt.Print(s)
+	s2 := fmt.Sprint("x", 1, 2)
+	s3 := fmt.Sprintln("x", 1, 2, true)
+	fmt.Print(s2, s3)
+	fmt.Println(fmt.Sprintf("%v=%v", "key", a))
+	fmt.Printf("100%% %q %v\n", "a\\b\n", "-n")
+}
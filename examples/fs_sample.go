@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	dir := "fs_test_dir"
+	os.RemoveAll(dir)
+	os.MkdirAll(dir+"/sub", 0755)
+	err := os.WriteFile(dir+"/a.txt", []byte("hello\nworld\n\n"), 0600)
+	fmt.Println("write", err == nil, err)
+	data, err := os.ReadFile(dir + "/a.txt")
+	fmt.Printf("read %q %v\n", string(data), err == nil)
+	_, err = os.ReadFile(dir + "/missing.txt")
+	fmt.Println("missing", err != nil, os.IsNotExist(err))
+	err = os.WriteFile(dir+"/nodir/x.txt", []byte("x"), 0644)
+	fmt.Println("nodir", os.IsNotExist(err))
+
+	st, err := os.Stat(dir + "/a.txt")
+	fmt.Println("stat", st.Name(), st.Size(), st.IsDir(), st.Mode().Perm() == 0600, st.ModTime().Unix() > 1600000000, err == nil)
+	st, err = os.Stat(dir + "/sub/")
+	fmt.Println("stat", st.Name(), st.IsDir(), st.Mode().IsDir(), st.Mode().IsRegular())
+	_, err = os.Stat(dir + "/nothing")
+	fmt.Println("stat", os.IsNotExist(err))
+
+	err = os.Chmod(dir+"/a.txt", 0644)
+	st, _ = os.Stat(dir + "/a.txt")
+	fmt.Println("chmod", err == nil, st.Mode().Perm() == 0644)
+	fmt.Println("mode", st.Mode(), st.Mode().Perm(), os.ModeDir|os.ModePerm)
+
+	err = os.Symlink("a.txt", dir+"/link")
+	fmt.Println("symlink", err == nil)
+	err = os.Symlink("a.txt", dir+"/link")
+	fmt.Println("symlink again", os.IsExist(err))
+	target, err := os.Readlink(dir + "/link")
+	fmt.Println("readlink", target, err == nil)
+	_, err = os.Readlink(dir + "/a.txt")
+	fmt.Println("readlink file", err != nil)
+	lst, _ := os.Lstat(dir + "/link")
+	st, _ = os.Stat(dir + "/link")
+	fmt.Println("lstat", lst.Name(), lst.Mode().IsRegular(), st.Mode().IsRegular(), st.Size())
+	fmt.Println("lstat", lst.Mode()&os.ModeSymlink != 0, st.Mode()&os.ModeSymlink != 0, lst.Mode()&os.ModeType == os.ModeSymlink)
+
+	entries, err := os.ReadDir(dir)
+	fmt.Println("readdir", len(entries), err == nil)
+	for _, e := range entries {
+		fmt.Println(e.Name(), e.IsDir(), e.Type().IsDir())
+	}
+	_, err = os.ReadDir(dir + "/none")
+	fmt.Println("readdir", os.IsNotExist(err))
+	_, err = os.Open(dir + "/none")
+	fmt.Println("open", os.IsNotExist(err))
+	nf, _ := os.Open(dir + "/none") // the error is ignored
+	if nf != nil {
+		nf.Close()
+	}
+	os.RemoveAll(dir)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Build with --return=var to print and update globals in functions returning a value.
+
+var calls = 0
+
+func fib(n int) int {
+	calls++
+	if n < 2 {
+		return n
+	}
+	return fib(n-1) + fib(n-2)
+}
+
+func greet(name string) string {
+	fmt.Println("greet", name)
+	return "Hello, " + name
+}
+
+func split(s string) (string, string) {
+	before, after, _ := strings.Cut(s, "=")
+	return before, after
+}
+
+func fields(s string) []string {
+	return strings.Split(s, ",")
+}
+
+func isEven(n int) bool {
+	return n%2 == 0
+}
+
+var hits = 0
+
+func hit(n int) bool {
+	hits++
+	fmt.Println("hit", n)
+	return n > 1
+}
+
+func main() {
+	fmt.Println("fib", fib(15), "calls", calls)
+	fmt.Println(greet("gopher") + "!")
+	if strings.HasPrefix(greet("world"), "Hello") {
+		fmt.Println("prefix ok")
+	}
+
+	k, v := split("key=value")
+	fmt.Println(k, v)
+	for i, f := range fields("a,b,c") {
+		fmt.Println(i, f, strings.ToUpper(f))
+	}
+
+	n := 0
+	for i := 0; i < fib(6); i++ {
+		if isEven(i) {
+			n += i
+		}
+	}
+	fmt.Println("sum", n)
+
+	if hit(2) || hit(1) {
+		fmt.Println("hits", hits)
+	}
+	if n > 100 && hit(3) {
+		fmt.Println("not reached")
+	}
+	fmt.Println(hit(0) || hit(1) && hit(2), hits)
+}
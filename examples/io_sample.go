@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	src := "io_sample_src.txt"
+	dst := "io_sample_dst.txt"
+	os.WriteFile(src, []byte("line 1\nline 2 * \\n\nno newline"), 0644)
+
+	in, _ := os.Open(src)
+	out, _ := os.Create(dst)
+	n, err := io.Copy(out, in)
+	fmt.Println("copied", n, err == nil)
+	in.Close()
+	out.Close()
+
+	in, _ = os.Open(dst)
+	data, err := io.ReadAll(in)
+	in.Close()
+	fmt.Printf("read %q %v\n", string(data), err == nil)
+
+	in, _ = os.Open(src)
+	n, err = io.Copy(os.Stdout, in)
+	in.Close()
+	fmt.Println()
+	fmt.Println("to stdout", n, err == nil)
+
+	in, _ = os.Open(src)
+	n, err = io.Copy(io.Discard, in)
+	in.Close()
+	fmt.Println("discarded", n, err == nil)
+
+	m, err := io.WriteString(os.Stdout, "héllo\n")
+	fmt.Println("written", m, err == nil)
+	fmt.Fprintln(io.Discard, "nothing")
+	io.WriteString(io.Discard, "nothing")
+
+	os.Remove(src)
+	os.Remove(dst)
+
+	w := io.MultiWriter(os.Stdout, os.Stdout)
+	io.WriteString(w, "twice\n")
+	fmt.Fprintln(w, "twice", 2)
+	fmt.Println("after twice")
+}
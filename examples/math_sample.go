@@ -72,6 +72,18 @@ func main() {
 	fmt.Printf("Asin %.10f\n", math.Asin(-0.5))
 	fmt.Printf("Acos %.10f\n", math.Acos(-0.5))
 
+	x := -2.5
+	fmt.Println(math.Floor(2.7), math.Floor(x), math.Ceil(2.1), math.Ceil(x), math.Round(x), math.Round(2.5), math.Trunc(x))
+	fmt.Println(math.Abs(x), math.Mod(7.5, 2), math.Mod(x, 2), math.Max(x, 1.5), math.Min(x, 1.5), math.Hypot(3, 4))
+	fmt.Println(math.Pow(-2, 3), math.Pow(2, -2), math.Pow(1.5, 2), math.Pow(3, 20))
+	fmt.Println(math.Log10(1000), math.Log2(8), math.Cbrt(27), math.Cbrt(-8), math.Floor(x)*2)
+	fmt.Printf("%.10f %.10f %.10f\n", math.Atan2(1, -1), math.Atan2(-1, -1), math.Pow(2, 0.5))
+	fmt.Printf("%.10f %.10f\n", math.Log10(2), math.Sqrt(math.Tan(1)))
+	fmt.Println(math.MaxInt, math.MinInt, math.MaxInt32, math.MinInt8)
+	inf := math.Inf(1)
+	fmt.Println(inf, math.Inf(-1), math.NaN(), math.IsInf(inf, 0), math.IsInf(inf, -1), math.IsNaN(math.NaN()))
+	fmt.Printf("%6.2f|%-5v|\n", inf, math.NaN())
+
 	v := Vector2{1.5, 1.5}
 	fmt.Printf("Len(): %.10f\n", v.Len())
 	fmt.Printf("Dot(): %.10f\n", v.Dot(Vector2{1.5, -1.5}))
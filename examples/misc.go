@@ -32,16 +32,6 @@ func returnStringAndStatus() (string, shell.StatusCode) {
 	return "aaa", 123
 }
 
-// Implements strings.Index()
-func GOTOSH_FUNC_strings_Index(s, f string) int {
-	i := 0
-	shell.Do(`_tmp=${s#*$f}; i=$((${#s}-${#_tmp}-${#f}))`)
-	if i < 0 {
-		return -1
-	}
-	return i
-}
-
 func returnStringAndStatus2() (shell.StatusCode, string) {
 	return 111, "bbb"
 }
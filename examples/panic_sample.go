@@ -0,0 +1,61 @@
+package main
+
+import "fmt"
+
+// Build with --checked to panic on out of range indexes and division by zero as Go does.
+
+func divide(a, b int) int {
+	return a / b
+}
+
+func at(i int, values []string) string {
+	return values[i]
+}
+
+func check(n int) {
+	if n < 0 {
+		panic(fmt.Sprintf("negative: %d", n))
+	}
+	fmt.Println("checked", n)
+}
+
+func positive(n int) bool {
+	check(n)
+	return n > 0
+}
+
+func try(name string, f func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Println(name, "recovered:", r)
+		}
+	}()
+	defer fmt.Println(name, "deferred")
+	f()
+	fmt.Println(name, "ok")
+}
+
+func main() {
+	values := []string{"a", "b", "c"}
+	try("divide", func() { fmt.Println(divide(7, 2)) })
+	try("zero", func() { fmt.Println(divide(1, 0)) })
+	try("index", func() { fmt.Println(at(1, values)) })
+	try("range", func() { fmt.Println(at(3, values)) })
+	try("check", func() { check(1) })
+	try("panic", func() { check(-1) })
+	try("condition", func() {
+		if positive(-2) {
+			fmt.Println("condition not reached")
+		}
+		fmt.Println("after condition not reached")
+	})
+	try("assign", func() { v := divide(3, 0) + 1; fmt.Println("assign not reached", v) })
+	if recover() == nil {
+		fmt.Println("not panicking")
+	}
+	s := "hello"
+	if len(s) > 9 && s[9:10] == "x" {
+		fmt.Println("not checked after &&")
+	}
+	fmt.Println("done")
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var emailPattern = regexp.MustCompile(`^([a-z0-9._-]+)@([a-z0-9-]+(?:\.[a-z0-9-]+)+)$`)
+
+func main() {
+	for _, s := range []string{"gopher@example.com", "bad@", "x.y@sub.example.org"} {
+		if emailPattern.MatchString(s) {
+			m := emailPattern.FindStringSubmatch(s)
+			fmt.Println("valid:", m[1], m[2], len(m))
+		} else {
+			fmt.Println("invalid:", s)
+		}
+	}
+
+	re := regexp.MustCompile(`(?i)go(pher)?s?`)
+	fmt.Println(re.FindString("I like Gophers and go"))
+	fmt.Println(strings.Join(re.FindAllString("go GOPHER gophers Go", -1), ","))
+	fmt.Println(len(re.FindAllString("go go go", 2)))
+	fmt.Println(re.MatchString("golang"), re.MatchString("rust"))
+
+	date := regexp.MustCompile(`(?P<year>\d{4})-(\d{2})-(\d{2})`)
+	fmt.Println(date.ReplaceAllString("from 2024-01-31 to 2024-12-25", "$3/$2/${year}"))
+	fmt.Println(date.ReplaceAllString("2024-01-31", "$$1 ${4}x $1x"))
+	fmt.Println(date.FindStringSubmatch("no date"))
+
+	sep := regexp.MustCompile(`\s*[,;]\s*`)
+	for _, f := range sep.Split("a, b;c ,d", -1) {
+		fmt.Println("field:", f)
+	}
+	parts := sep.Split("a,b,c", 2)
+	fmt.Println(len(parts), parts[1])
+
+	empty := regexp.MustCompile(`x*`)
+	fmt.Println(empty.ReplaceAllString("abc", "-"))
+	fmt.Println(strings.Join(empty.Split("axxbc", -1), "|"))
+	fmt.Println(strings.Join(regexp.MustCompile(`a|b`).FindAllString("cabbage", -1), ""))
+	fmt.Println(regexp.MustCompile(`[\]\-^\[]+`).FindString("ab]-^[cd"))
+	fmt.Println(regexp.MustCompile(`o$`).ReplaceAllString("foo boo", "0"))
+	fmt.Println(regexp.MustCompile(`^\s+`).ReplaceAllString("   trim  ", ""))
+
+	word := regexp.MustCompile(`^c.f.$`)
+	fmt.Println(word.MatchString("café"), word.FindString("cafe"), regexp.MustCompile(`[^a-z]`).FindString("naïve"))
+	fmt.Println(strings.Join(regexp.MustCompile(`.`).FindAllString("日本語", -1), " "))
+}
@@ -0,0 +1,51 @@
+package main
+
+import "fmt"
+
+type Endpoint struct {
+	Host string
+	Port int
+}
+
+func main() {
+	var ep Endpoint
+	n, err := fmt.Sscanf("example.com 8080", "%s %d", &ep.Host, &ep.Port)
+	fmt.Println(n, err == nil, ep.Host, ep.Port)
+
+	var major, minor, patch int
+	n, err = fmt.Sscanf("v1.22.-3", "v%d.%d.%d", &major, &minor, &patch)
+	fmt.Println(n, err == nil, major, minor, patch)
+
+	var msg string
+	var count int
+	n, err = fmt.Sscanf(`"hello \"world\"" 42`, "%q %d", &msg, &count)
+	fmt.Println(n, err == nil, msg, count)
+
+	var ratio float64
+	var enabled bool
+	var name string
+	n, err = fmt.Sscan("3.5 true\n  alice", &ratio, &enabled, &name)
+	fmt.Println(n, err == nil, ratio, enabled, name)
+
+	p := &count
+	n, err = fmt.Sscan("007", p)
+	fmt.Println(n, err == nil, count)
+
+	n, err = fmt.Sscan("abc", &count)
+	fmt.Println(n, err == nil, count)
+	n, err = fmt.Sscanf("port=80", "host=%d", &count)
+	fmt.Println(n, err == nil, count)
+	n, err = fmt.Sscan("1", &major, &minor)
+	fmt.Println(n, err == nil, major)
+
+	// stdin is scan_sample.stdin: the newline after the value read by Scan is consumed.
+	var first, second, line string
+	n, err = fmt.Scanln(&first, &second)
+	fmt.Println(n, err == nil, first, second)
+	n, err = fmt.Scanln(&line)
+	fmt.Println(n, err == nil, line)
+	n, err = fmt.Scan(&count)
+	fmt.Println(n, err == nil, count)
+	n, err = fmt.Scanln(&line)
+	fmt.Println(n, err == nil, line)
+}
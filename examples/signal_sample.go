@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func work(name string) {
+	defer fmt.Println("work done", name)
+	if name == "" {
+		fmt.Println("nothing to do")
+		return
+	}
+	for i := 0; i < 2; i++ {
+		defer fmt.Println("step", i, "of", name)
+	}
+	fmt.Println("working", name)
+}
+
+func interrupt() {
+	syscall.Kill(os.Getpid(), syscall.SIGINT)
+}
+
+func main() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer fmt.Println("cleanup in main")
+	defer func() {
+		fmt.Println("deferred last runs first")
+	}()
+
+	work("a")
+	work("")
+
+	syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	sig := <-sigs
+	fmt.Println("received", sig)
+
+	go interrupt()
+	sig = <-sigs
+	fmt.Println("received", sig.String(), sig == os.Interrupt)
+
+	signal.Ignore(syscall.SIGHUP)
+	syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	signal.Reset(os.Interrupt, syscall.SIGTERM)
+
+	ch := make(chan string, 2)
+	ch <- "hello world"
+	msg := <-ch
+	fmt.Println(msg)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+func main() {
+	names := []string{"bob", "Alice", "carol", "Bob", "alice", "_x", "Zed", "émile", "10", "9"}
+	sort.Strings(names)
+	fmt.Println(strings.Join(names, " "))
+
+	nums := []int{42, -7, 0, 13, 100, -100, 7}
+	sort.Ints(nums)
+	fmt.Println(nums)
+
+	words := []string{"pear", "fig", "banana", "kiwi", "apple", "date"}
+	sort.Slice(words, func(i, j int) bool { return len(words[i]) < len(words[j]) })
+	fmt.Println(words)
+	sort.SliceStable(nums, func(i, j int) bool { return nums[i]*nums[i] > nums[j]*nums[j] })
+	fmt.Println(nums)
+
+	s := []int{3, 1, 3, 3, 2, 2, 1}
+	slices.Sort(s)
+	fmt.Println(s, slices.Contains(s, 2), slices.Contains(s, 5), slices.Index(s, 3), slices.Index(s, 9))
+	s = slices.Compact(s)
+	fmt.Println(s, len(s))
+	slices.Reverse(s)
+	fmt.Println(s, slices.Max(s), slices.Min(s))
+	fmt.Println(slices.Max(names), slices.Min(names))
+	if slices.Contains(names, "Zed") {
+		fmt.Println("found Zed at", slices.Index(names, "Zed"))
+	}
+
+	ages := map[string]int{"carol": 35, "alice": 30, "Bob": 25, "dave": 40}
+	keys := slices.Sorted(maps.Keys(ages))
+	for _, k := range keys {
+		fmt.Println(k, ages[k])
+	}
+	fmt.Println(len(slices.Collect(maps.Keys(ages))))
+}
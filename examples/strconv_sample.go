@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func main() {
+	for _, s := range []string{"12", "-7", "+3", "abc", "", "1.5", " 1", "9223372036854775807", "9223372036854775808", "-9223372036854775809"} {
+		n, err := strconv.Atoi(s)
+		fmt.Printf("Atoi(%q) = %d %v\n", s, n, err == nil)
+	}
+
+	v, err := strconv.ParseInt("ff", 16, 64)
+	fmt.Println("ParseInt", v, err == nil)
+	v, err = strconv.ParseInt("0x1_f", 0, 64)
+	fmt.Println("ParseInt", v, err == nil)
+	v, err = strconv.ParseInt("-0b101", 0, 8)
+	fmt.Println("ParseInt", v, err == nil)
+	v, err = strconv.ParseInt("017", 0, 64)
+	fmt.Println("ParseInt", v, err == nil)
+	v, err = strconv.ParseInt("Zz", 36, 64)
+	fmt.Println("ParseInt", v, err == nil)
+	v, err = strconv.ParseInt("200", 10, 8)
+	fmt.Println("ParseInt", v, err == nil)
+	v, err = strconv.ParseInt("-129", 10, 8)
+	fmt.Println("ParseInt", v, err == nil)
+	v, err = strconv.ParseInt("12a", 10, 64)
+	fmt.Println("ParseInt", v, err == nil)
+
+	f, err := strconv.ParseFloat("3.25", 64)
+	fmt.Println("ParseFloat", f, err == nil)
+	f, err = strconv.ParseFloat("-1.5e3", 64)
+	fmt.Println("ParseFloat", f, err == nil)
+	f, err = strconv.ParseFloat("25e-4", 64)
+	fmt.Println("ParseFloat", f, err == nil)
+	f, err = strconv.ParseFloat("007.50", 64)
+	fmt.Println("ParseFloat", f*2, err == nil)
+	f, err = strconv.ParseFloat("1.2.3", 64)
+	fmt.Println("ParseFloat", f, err == nil)
+	f, err = strconv.ParseFloat("1e", 64)
+	fmt.Println("ParseFloat", f, err == nil)
+
+	b, err := strconv.ParseBool("TRUE")
+	fmt.Println("ParseBool", b, err == nil)
+	b, err = strconv.ParseBool("yes")
+	fmt.Println("ParseBool", b, err == nil)
+
+	fmt.Println("FormatInt", strconv.FormatInt(255, 16), strconv.FormatInt(-255, 2), strconv.FormatInt(35, 36), strconv.FormatInt(0, 10))
+	fmt.Println("FormatFloat", strconv.FormatFloat(3.14159, 'f', 2, 64), strconv.FormatFloat(1234.5678, 'e', 3, 64), strconv.FormatFloat(0.1, 'g', -1, 64))
+	fmt.Println("FormatFloat", strconv.FormatFloat(1500000, 'f', -1, 64), strconv.FormatFloat(1234.5, 'e', -1, 64), strconv.FormatFloat(1e21, 'g', -1, 64))
+
+	q := strconv.Quote("say \"hi\"\n\ttab\\ \x01")
+	fmt.Println("Quote", q)
+	u, err := strconv.Unquote(q)
+	fmt.Println("Unquote", u == "say \"hi\"\n\ttab\\ \x01", err == nil)
+	u, err = strconv.Unquote("\"\\x41\\u00e9\\101\"")
+	fmt.Println("Unquote", u, err == nil)
+	u, err = strconv.Unquote("`raw\\n`")
+	fmt.Println("Unquote", u, err == nil)
+	u, err = strconv.Unquote("'a'")
+	fmt.Println("Unquote", u, err == nil)
+	u, err = strconv.Unquote("\"abc")
+	fmt.Println("Unquote", u, err == nil)
+	u, err = strconv.Unquote("\"a\\'\"")
+	fmt.Println("Unquote", u, err == nil)
+}
@@ -38,6 +38,55 @@ func main() {
 
 	fmt.Println("msg:", getMessage("foobar"))
 
+	path := "/usr/local/bin/gotosh"
+	fmt.Println("has prefix", strings.HasPrefix(path, "/usr"), strings.HasPrefix(path, "usr"))
+	fmt.Println("has suffix", strings.HasSuffix(path, "gotosh"), strings.HasSuffix(path, "bin"))
+	fmt.Println("index", strings.Index(path, "/"), strings.Index(path, "bin"), strings.Index(path, "*"))
+	fmt.Println("last index", strings.LastIndex(path, "/"), strings.LastIndex(path, "x"))
+	fmt.Println("count", strings.Count(path, "/"), strings.Count("cheese", "e"), strings.Count("five", ""))
+	fmt.Println("repeat", strings.Repeat("ab", 5)+"|"+strings.Repeat("x", 0)+"|")
+	fmt.Println("fields", strings.Fields("  foo bar\tbaz  "))
+	csv := "a b,,*,c"
+	cols := strings.Split(csv, ",")
+	fmt.Println("split", len(cols), cols)
+	for i, c := range strings.Split("x::y::", "::") {
+		fmt.Printf("split[%d]=%q\n", i, c)
+	}
+	fmt.Println("split empty", len(strings.Split("", ",")), len(strings.Split("", "")), strings.Split("abc", ""))
+	fmt.Println("join", strings.Join(cols, " | "), strings.Join([]string{"a", "b"}, "--"))
+	fmt.Println("splitN", strings.SplitN("a,b,c,d", ",", 2), strings.SplitN("a,b,c,d", ",", -1))
+	fmt.Println("nested", strings.Join(strings.Split("*,a", ","), "+"), strings.Join(strings.Split("x y,z", ","), "+"), len(strings.Split("x y,*", ",")))
+	dir, file, found := strings.Cut(path, "/bin/")
+	fmt.Println("cut", dir, file, found)
+	_, _, found = strings.Cut(path, "[*]")
+	fmt.Println("cut", found)
+	fmt.Println("equal fold", strings.EqualFold("Go", "GO"), strings.EqualFold("Go", "Gopher"))
+	fmt.Println("title", strings.Title("her royal highness, the_queen"))
+	fmt.Println("trim", strings.Trim("xx*hello*xx", "x*"), strings.TrimLeft("xx*hello*xx", "x*"), strings.TrimRight("xx*hello*xx", "x*"))
+	fmt.Println("replace", strings.Replace("oink oink oink", "k", "ky", 2), strings.Replace("oink oink oink", "oink", "moo", -1))
+	fmt.Println("map", strings.Map(func(r rune) rune {
+		if r == 32 {
+			return -1
+		}
+		return r + 1
+	}, "HAL 9000"))
+
+	lines := "x\ny\n\n"
+	fmt.Printf("newline %q %q %q\n", strings.Repeat("x\n", 2), strings.ReplaceAll(lines, "y", "z"), strings.TrimLeft(lines, "x"))
+	fmt.Printf("newline %q %q %q %q\n", strings.ToUpper(lines), strings.TrimPrefix(lines, "x"), strings.Title(lines), strings.Replace(lines, "\n", ",", 1))
+	fmt.Printf("newline %q %q %q\n", strings.TrimSpace(lines), strings.Join([]string{"a\n", "\n"}, "\n"), strings.Split(lines, "\n"))
+	fmt.Println("newline", strings.Index(lines, "\n\n"), strings.HasSuffix(lines, "\n"), strings.ReplaceAll("a*b*", "*", "&"))
+
+	var sb strings.Builder
+	for i := 3; i > 0; i-- {
+		sb.WriteString(strconv.Itoa(i))
+		sb.WriteString("...")
+	}
+	sb.WriteString("ignition")
+	fmt.Println("builder", sb.String(), sb.Len())
+	sb.Reset()
+	fmt.Println("builder", sb.Len())
+
 	fmt.Print(123, 456)
 	fmt.Println("#Println#", 123)
 	fmt.Printf("#Printf %d %04d %s\n", 123, 45, "#test#")
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func show(d time.Duration) {
+	fmt.Println(d.String())
+}
+
+func main() {
+	start := time.Now()
+	time.Sleep(20 * time.Millisecond)
+	elapsed := time.Since(start)
+	fmt.Println("slept", elapsed >= 20*time.Millisecond, elapsed < 5*time.Second)
+
+	show(90 * time.Second)
+	show(time.Hour + 2*time.Minute + 3500*time.Millisecond)
+	show(1500 * time.Microsecond)
+	show(42 * time.Nanosecond)
+	show(-2 * time.Minute)
+	show(0)
+	var d time.Duration = 2500 * time.Millisecond
+	fmt.Println(d.Milliseconds(), d.String())
+	n := 3
+	d = time.Duration(n) * time.Minute
+	fmt.Println(d, d.Seconds())
+	fmt.Printf("%v|%s\n", d, d)
+
+	t := time.Unix(1700000000, 123456789)
+	fmt.Println(t.Unix(), t.UnixMilli())
+	for _, layout := range []string{time.RFC3339, time.RFC3339Nano, time.DateTime, time.Kitchen, time.ANSIC, time.RFC1123, time.StampMilli, "Monday, January 2 2006 _2 002 3:4:5 pm .000000 .999", "06/1/2 15h%"} {
+		fmt.Println(t.Format(layout))
+	}
+	u := t.Add(36 * time.Hour)
+	fmt.Println(u.Sub(t).String(), u.After(t), u.Before(t), t.Equal(time.Unix(1700000000, 123456789)))
+
+	inputs := []string{"2024-02-29T13:45:10Z", "2024-02-29T13:45:10.25+09:00", "2023-02-29T00:00:00Z", "bad"}
+	for _, s := range inputs {
+		p, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			fmt.Println("parse error", s)
+			continue
+		}
+		fmt.Println(p.UnixNano(), p.Format(time.RFC1123Z), p.Add(time.Hour).Format(time.Kitchen))
+	}
+	k, err := time.Parse("Jan _2 2006 3:04PM", "Mar  5 1999 11:30PM")
+	fmt.Println(k.Unix(), err == nil)
+	k, err = time.Parse(time.DateOnly, "2000-13-01")
+	fmt.Println(err != nil)
+}
@@ -40,23 +40,6 @@ const Cyan = 6
 const White = 7
 const Default = 9
 
-func GOTOSH_FUNC_strings_Index(s, f string) int {
-	i := 0
-	_ = shell.Do(`_tmp=${s#*$f}; i=$((${#s}-${#_tmp}-${#f}))`)
-	if i < 0 {
-		return -1
-	}
-	return i
-}
-
-func GOTOSH_FUNC_strings_Repeat(s string, n int) string {
-	ret := ""
-	for i := 0; i < n; i++ {
-		ret += s
-	}
-	return ret
-}
-
 func ConsoleSize() (int, int) {
 	sz, _ := shell.Exec("stty", "size")
 	if p := strings.Index(sz, " "); p > 0 {
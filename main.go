@@ -1,15 +1,44 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/binzume/gotosh/compiler"
 )
 
+// completion generates a completion script for the program: gotosh completion --shell=bash prog.go
+func completion(args []string) error {
+	fs := flag.NewFlagSet("completion", flag.ExitOnError)
+	shell := fs.String("shell", "bash", "`shell` to generate the script for (bash or zsh)")
+	name := fs.String("name", "", "`command` name to complete (default: the first source file without .go)")
+	fs.Parse(args)
+	if *name == "" && fs.NArg() > 0 {
+		*name = strings.TrimSuffix(filepath.Base(fs.Arg(0)), ".go")
+	}
+	return compiler.CompletionFiles(*shell, *name, fs.Args())
+}
+
 func main() {
-	err := compiler.CompileFiles(os.Args[1:])
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "completion" {
+		err = completion(os.Args[2:])
+	} else {
+		flag.StringVar(&compiler.FloatBackend, "float", compiler.FloatBackend, "`command` to evaluate float expressions: bc, awk, auto (bc if installed, otherwise awk) or coproc (a bc process for the whole script)")
+		flag.StringVar(&compiler.ReturnConvention, "return", compiler.ReturnConvention, "`convention` to return a value from functions: stdout (called as $(f)) or var (GOTOSH_RET_0, without forking)")
+		flag.BoolVar(&compiler.Optimize, "optimize", compiler.Optimize, "drop unused functions and variables, and inline small functions")
+		flag.BoolVar(&compiler.LineComments, "lines", compiler.LineComments, "write the Go source position of each statement as a comment")
+		flag.BoolVar(&compiler.Debug, "debug", compiler.Debug, "print the failed command and the Go function stack when a command fails")
+		flag.BoolVar(&compiler.Checked, "checked", compiler.Checked, "panic on out of range indexes, integer division by zero and nil pointer dereferences")
+		flag.BoolVar(&compiler.Trace, "trace", compiler.Trace, "print the Go source lines to stderr as they run")
+		flag.Parse()
+		err = compiler.CompileFiles(flag.Args())
+	}
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
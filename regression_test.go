@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"flag"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -13,6 +14,7 @@ import (
 	"time"
 )
 
+// regressionExamples are the examples to compare with Go. Compiler flags may follow the name.
 var regressionExamples = []string{
 	"hello_world",
 	"fizz_buzz",
@@ -21,16 +23,58 @@ var regressionExamples = []string{
 	"file_io",
 	"exec_pipe",
 	"math_sample",
+	"math_sample --float=awk",
+	"math_sample --float=coproc",
+	"float_inf",
+	"float_inf --float=awk",
+	"float_inf --float=coproc",
+	"func_sample --return=var",
+	"string_sample --return=var",
+	"slice_sample --return=var",
+	"misc --optimize",
+	"string_sample --optimize",
+	"time_sample --optimize",
+	"func_sample --return=var --optimize",
+	"lambda_sample --lines",
+	"fizz_buzz --debug",
+	"strconv_sample --debug",
+	"fs_sample --debug",
+	"panic_sample --checked",
+	"panic_sample --checked --return=var",
 	"lambda_sample",
 	"misc",
+	"fmt_sample",
+	"scan_sample",
+	"strconv_sample",
+	"fs_sample",
+	"filepath_sample",
+	"bufio_sample",
+	"io_sample",
+	"exec_sample",
+	"exec_pipe_cmd",
+	"exec_pipe_status",
+	"time_sample",
+	"signal_sample",
+	"flag_sample",
+	"flag_posix",
 	// bash only
 	"pointer_sample",
 	"map_sample",
+	"slice_sample",
+	"regexp_sample",
+	"sort_sample",
+}
+
+// posixExamples are the examples which also run under a POSIX shell.
+var posixExamples = []string{
+	"hello_world",
+	"flag_posix",
 }
 
 const regressionTimeout = 30 * time.Second
 
 var regressionShell = flag.String("shell", defaultRegressionShell(), "shell command for regression tests")
+var posixShell = flag.String("posix-shell", "dash", "POSIX shell command for regression tests")
 
 func defaultRegressionShell() string {
 	if shell := os.Getenv("TEST_SHELL"); shell != "" {
@@ -43,22 +87,29 @@ func defaultRegressionShell() string {
 }
 
 func TestRegression(t *testing.T) {
-	input, err := os.ReadFile("go.mod")
-	if err != nil {
-		t.Fatal(err)
-	}
+	testRegression(t, *regressionShell, regressionExamples)
+}
 
-	for _, name := range regressionExamples {
-		t.Run(name, func(t *testing.T) {
+func TestRegressionPOSIX(t *testing.T) {
+	testRegression(t, *posixShell, posixExamples)
+}
+
+func testRegression(t *testing.T, shell string, examples []string) {
+	for _, example := range examples {
+		t.Run(example, func(t *testing.T) {
 			ctx, cancel := context.WithTimeout(context.Background(), regressionTimeout)
 			defer cancel()
 
-			script, transpileStderr := runCommand(t, ctx, input, "go", "run", ".", filepath.Join("examples", name+".go"))
+			args := strings.Fields(example)
+			name := args[0]
+			input := exampleInput(t, name)
+			args = append(append([]string{"run", "."}, args[1:]...), filepath.Join("examples", name+".go"))
+			script, transpileStderr := runCommand(t, ctx, input, "go", args...)
 			if transpileStderr != "" {
 				t.Errorf("transpiler wrote to stderr: %q", transpileStderr)
 			}
 			want, _ := runCommand(t, ctx, input, "go", "run", filepath.Join("examples", name+".go"), "aa", "bb", "123", "456")
-			got := runShell(t, ctx, script)
+			got := runShell(t, ctx, shell, script, input)
 			if !bytes.Equal(want, got) {
 				t.Errorf("output mismatch (-want +got):\nwant:\n%s\ngot:\n%s", want, got)
 			}
@@ -66,6 +117,174 @@ func TestRegression(t *testing.T) {
 	}
 }
 
+// TestRegressionWithoutBashVersion runs examples without BASH_VERSION, so the runtime takes its branches for other shells,
+// such as the status files of shell.ExecPipeAll() and grep/sed for regexp.
+func TestRegressionWithoutBashVersion(t *testing.T) {
+	for _, name := range []string{"exec_pipe_status", "regexp_sample"} {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), regressionTimeout)
+			defer cancel()
+			src := filepath.Join("examples", name+".go")
+			script, _ := runCommand(t, ctx, nil, "go", "run", ".", src)
+			input := exampleInput(t, name)
+			want, _ := runCommand(t, ctx, input, "go", "run", src, "aa", "bb", "123", "456")
+			got := runShell(t, ctx, *regressionShell, append([]byte("unset BASH_VERSION\n"), script...), input)
+			if !bytes.Equal(want, got) {
+				t.Errorf("output mismatch (-want +got):\nwant:\n%s\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+// fakeBc evaluates the expressions sent by the float.bc runtime with awk: the coprocess is tested without bc.
+const fakeBc = `#!/bin/sh
+while IFS= read -r line; do
+  case $line in
+    '"') read -r line; echo ;;
+    *) awk "BEGIN { printf \"%.17g\\n\", (${line#scale=*;}) }" ;;
+  esac
+done
+`
+
+const floatCoprocSource = `package main
+
+import (
+	"fmt"
+	"math"
+)
+
+func half(x float64) float64 {
+	return x / 2
+}
+
+func main() {
+	var a float64 = 1.5
+	var b = 2.25
+	c := a*2 + b*(a+1)
+	d := math.Sqrt(a*b) + half(a+1)
+	if a > 1 && b/a > 1.2 {
+		fmt.Println("yes")
+	}
+	for i := 0; i < 4; i++ {
+		x := float64(i) / 4
+		fmt.Println(x * a)
+	}
+	fmt.Println(c, d > 3, half(b)*3)
+	fmt.Printf("%.3f\n", a*b*2)
+}
+`
+
+func TestFloatCoproc(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), regressionTimeout)
+	defer cancel()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "bc"), []byte(fakeBc), 0755); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(dir, "float_coproc.go")
+	if err := os.WriteFile(src, []byte(floatCoprocSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script, _ := runCommand(t, ctx, nil, "go", "run", ".", "--float=coproc", src)
+	if bytes.Contains(script, []byte("$(GOTOSH_RT_float__bc")) {
+		t.Errorf("float expressions are evaluated in subshells:\n%s", script)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	got := runShell(t, ctx, *regressionShell, script, nil)
+	want := "yes\n0\n0.375\n0.75\n1.125\n8.625 true 3.375\n6.750\n"
+	if string(got) != want {
+		t.Errorf("output mismatch:\nwant:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+const readNULSource = `package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	data, err := os.ReadFile(%[1]q)
+	fmt.Println("ReadFile", len(data), err != nil)
+	f, _ := os.Open(%[1]q)
+	data, err = io.ReadAll(f)
+	f.Close()
+	fmt.Println("ReadAll", len(data), err != nil)
+}
+`
+
+func TestReadNUL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), regressionTimeout)
+	defer cancel()
+	dir := t.TempDir()
+	data := filepath.Join(dir, "nul.bin")
+	if err := os.WriteFile(data, []byte("a\x00b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(dir, "read_nul.go")
+	if err := os.WriteFile(src, []byte(fmt.Sprintf(readNULSource, data)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script, _ := runCommand(t, ctx, nil, "go", "run", ".", src)
+	got := string(runShell(t, ctx, *regressionShell, script, nil))
+	for _, want := range []string{"ReadFile 1 true\n", "ReadAll 1 true\n", "os.ReadFile: " + data + ": NUL bytes are not supported\n", "io.ReadAll: NUL bytes are not supported\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+const emptyMaxSource = `package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+func main() {
+	var n []int
+	fmt.Println("before")
+	fmt.Println(slices.Max(n))
+	fmt.Println("after")
+}
+`
+
+func TestEmptySliceMax(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), regressionTimeout)
+	defer cancel()
+	src := filepath.Join(t.TempDir(), "empty_max.go")
+	if err := os.WriteFile(src, []byte(emptyMaxSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script, _ := runCommand(t, ctx, nil, "go", "run", ".", src)
+	if _, err := exec.LookPath(*regressionShell); err != nil {
+		t.Skipf("%s is required to run regression tests: %v", *regressionShell, err)
+	}
+	out, err := exec.CommandContext(ctx, *regressionShell, "-c", string(script)).CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 2 {
+		t.Errorf("exit status = %v, want 2", err)
+	}
+	want := "before\npanic: slices.Max: empty list\n"
+	if string(out) != want {
+		t.Errorf("output mismatch:\nwant:\n%s\ngot:\n%s", want, out)
+	}
+}
+
+// exampleInput returns examples/NAME.stdin, which is given to both Go and the shell. It defaults to go.mod.
+func exampleInput(t *testing.T, name string) []byte {
+	t.Helper()
+	input, err := os.ReadFile(filepath.Join("examples", name+".stdin"))
+	if os.IsNotExist(err) {
+		input, err = os.ReadFile("go.mod")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input
+}
+
 func runCommand(t *testing.T, ctx context.Context, input []byte, name string, args ...string) ([]byte, string) {
 	t.Helper()
 	cmd := exec.CommandContext(ctx, name, args...)
@@ -79,21 +298,22 @@ func runCommand(t *testing.T, ctx context.Context, input []byte, name string, ar
 	return out, stderr.String()
 }
 
-func runShell(t *testing.T, ctx context.Context, script []byte) []byte {
+// runShell runs the script with the input on stdin. The script is passed with -c so that it can read stdin.
+func runShell(t *testing.T, ctx context.Context, shell string, script, input []byte) []byte {
 	t.Helper()
-	parts := strings.Fields(*regressionShell)
+	parts := strings.Fields(shell)
 	if len(parts) == 0 {
-		t.Fatal("-shell must specify a command")
+		t.Fatal("the shell must specify a command")
 	}
-	args := []string{"-u", "-s", "--", "aa", "bb", "123", "456"}
 	command := parts[0]
+	args := []string{"-u", "-c", string(script), command, "aa", "bb", "123", "456"}
 	args = append(parts[1:], args...)
 	if _, err := exec.LookPath(command); err != nil {
 		t.Skipf("%s is required to run regression tests: %v", command, err)
 	}
 
 	cmd := exec.CommandContext(ctx, command, args...)
-	cmd.Stdin = bytes.NewReader(script)
+	cmd.Stdin = bytes.NewReader(input)
 	cmd.Env = os.Environ()
 	out, err := cmd.CombinedOutput()
 	if err != nil {
@@ -1,6 +1,7 @@
 package shell
 
 import (
+	"errors"
 	"io"
 	"os"
 	"os/exec"
@@ -8,11 +9,13 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
 type TempVarInt = int
 type TempVarString = string
+type TempVarBool = bool
 
 type StatusCode byte
 
@@ -27,12 +30,31 @@ type Stage func(in, out *os.File, args ...string) StatusCode
 type StageCall struct {
 	fn   Stage
 	args []string
+	cmd  *exec.Cmd
 }
 
 func Bind(fn Stage, args ...string) StageCall {
 	return StageCall{fn: fn, args: args}
 }
 
+// Cmd binds an external command as a stage. Its stderr is inherited as in a
+// shell pipeline.
+func Cmd(name string, args ...string) StageCall {
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = os.Stderr
+	return StageCall{cmd: cmd}
+}
+
+// BindCmd binds an *exec.Cmd as a stage. Stdin and Stdout are replaced with
+// the pipeline's pipes; other fields such as Env, Dir and Stderr are kept.
+// Stderr defaults to os.Stderr as with Cmd, like the shell script.
+func BindCmd(cmd *exec.Cmd) StageCall {
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	return StageCall{cmd: cmd}
+}
+
 func (s StatusCode) Error() string {
 	return strconv.Itoa(int(s))
 }
@@ -115,6 +137,16 @@ func ExecPipe(stages ...StageCall) StatusCode {
 	if len(stages) == 0 {
 		return 0
 	}
+	statuses := ExecPipeAll(stages...)
+	return statuses[len(statuses)-1]
+}
+
+// ExecPipeAll is like ExecPipe but returns the status of every stage, as
+// PIPESTATUS does in bash.
+func ExecPipeAll(stages ...StageCall) []StatusCode {
+	if len(stages) == 0 {
+		return []StatusCode{}
+	}
 
 	inputs := make([]*os.File, len(stages))
 	outputs := make([]*os.File, len(stages))
@@ -129,7 +161,10 @@ func ExecPipe(stages ...StageCall) StatusCode {
 			for _, f := range created {
 				_ = f.Close()
 			}
-			return 1
+			for i := range statuses {
+				statuses[i] = 1
+			}
+			return statuses
 		}
 		inputs[i+1] = r
 		outputs[i] = w
@@ -147,6 +182,10 @@ func ExecPipe(stages ...StageCall) StatusCode {
 			if outputs[i] != os.Stdout {
 				defer outputs[i].Close()
 			}
+			if stage.cmd != nil {
+				statuses[i] = runStageCmd(stage.cmd, inputs[i], outputs[i])
+				return
+			}
 			if stage.fn == nil {
 				statuses[i] = 1
 				return
@@ -156,7 +195,26 @@ func ExecPipe(stages ...StageCall) StatusCode {
 	}
 
 	wg.Wait()
-	return statuses[len(statuses)-1]
+	return statuses
+}
+
+func runStageCmd(cmd *exec.Cmd, in, out *os.File) StatusCode {
+	cmd.Stdin = in
+	cmd.Stdout = out
+	err := cmd.Run()
+	if err == nil {
+		return 0
+	}
+	if ee, ok := err.(*exec.ExitError); ok {
+		if ws, ok := ee.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			return StatusCode(128 + int(ws.Signal()))
+		}
+		return StatusCode(ee.ExitCode())
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return 127
+	}
+	return 1
 }
 
 func Do(rawScript string) StatusCode {
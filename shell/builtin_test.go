@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"io"
 	"os"
+	"os/exec"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -52,3 +54,74 @@ func TestExecPipe(t *testing.T) {
 		t.Fatalf("ExecPipe output = %q, want %q", got, "HELLO!\n")
 	}
 }
+
+func TestExecPipeCmd(t *testing.T) {
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	t.Cleanup(func() {
+		os.Stdout = oldStdout
+		_ = r.Close()
+		_ = w.Close()
+	})
+
+	status := ExecPipe(
+		Bind(func(_ *os.File, out *os.File, _ ...string) StatusCode {
+			_, _ = out.WriteString("b\na\nb\n")
+			return 0
+		}),
+		Cmd("sort", "-u"),
+		BindCmd(exec.Command("tr", "a-z", "A-Z")),
+	)
+	if status != 0 {
+		t.Fatalf("ExecPipe status = %d, want 0", status)
+	}
+	if status := ExecPipe(Cmd("sh", "-c", "exit 3")); status != 3 {
+		t.Fatalf("ExecPipe status = %d, want 3", status)
+	}
+	if status := ExecPipe(Cmd("gotosh-missing-command")); status != 127 {
+		t.Fatalf("ExecPipe status = %d, want 127", status)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "A\nB\n" {
+		t.Fatalf("ExecPipe output = %q, want %q", got, "A\nB\n")
+	}
+}
+
+func TestBindCmdStderr(t *testing.T) {
+	if stage := BindCmd(exec.Command("true")); stage.cmd.Stderr != os.Stderr {
+		t.Errorf("BindCmd Stderr = %v, want os.Stderr", stage.cmd.Stderr)
+	}
+	cmd := exec.Command("true")
+	cmd.Stderr = io.Discard
+	if stage := BindCmd(cmd); stage.cmd.Stderr != io.Discard {
+		t.Errorf("BindCmd Stderr = %v, want the Stderr of the command", stage.cmd.Stderr)
+	}
+}
+
+func TestExecPipeAll(t *testing.T) {
+	statuses := ExecPipeAll(
+		Cmd("sh", "-c", "exit 2"),
+		Bind(func(in, out *os.File, _ ...string) StatusCode {
+			_, _ = io.Copy(io.Discard, in)
+			return 5
+		}),
+		Cmd("true"),
+	)
+	if want := []StatusCode{2, 5, 0}; !slices.Equal(statuses, want) {
+		t.Fatalf("ExecPipeAll statuses = %v, want %v", statuses, want)
+	}
+	if statuses := ExecPipeAll(); len(statuses) != 0 {
+		t.Fatalf("ExecPipeAll() = %v, want empty", statuses)
+	}
+}